@@ -0,0 +1,81 @@
+package quester
+
+import (
+	"cmp"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Accept sets the Accept header of the request to the given media types,
+// which may carry q parameters. Responses are decoded with the registered
+// decoder for their actual Content-Type; the accepted types are only used
+// when the Content-Type is missing or generic, preferring higher q values.
+func (r *Request) Accept(contentTypes ...string) *Request {
+	return r.SetHeader("Accept", strings.Join(contentTypes, ", "))
+}
+
+// AcceptEncoding sets the Accept-Encoding header to the given encodings.
+// Responses encoded with gzip or deflate are decompressed; other encodings
+// are returned as received.
+func (r *Request) AcceptEncoding(encodings ...string) *Request {
+	return r.SetHeader("Accept-Encoding", strings.Join(encodings, ", "))
+}
+
+// SetDefaultAccept sets the Accept header sent when a request does not set
+// one.
+func (c *Client) SetDefaultAccept(contentType string) {
+	if c.Headers == nil {
+		c.Headers = http.Header{}
+	}
+	c.Headers.Set("Accept", contentType)
+}
+
+// acceptedType returns the most preferred accepted media type with a
+// decoder, used to decode responses whose Content-Type is missing or generic.
+func (r *Request) acceptedType() string {
+	accept := r.headers.Get("Accept")
+	if accept == "" {
+		accept = r.client.Headers.Get("Accept")
+	}
+
+	type candidate struct {
+		mediaType string
+		q         float64
+	}
+	var candidates []candidate
+	for _, v := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if _, ok := r.client.decoder(mt); ok && q > 0 {
+			candidates = append(candidates, candidate{mt, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	// Stable, so equally preferred types keep their order
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.q, a.q)
+	})
+	return candidates[0].mediaType
+}
+
+// ambiguousType reports whether contentType does not identify a format.
+func ambiguousType(contentType string) bool {
+	switch mediaType(contentType) {
+	case "", "application/octet-stream", "text/plain":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package quester
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffStrategy computes the delay before a retry from the (1-based)
+// attempt that failed and the previous delay, which is zero before the
+// first retry.
+type BackoffStrategy interface {
+	Backoff(attempt int, prev time.Duration) time.Duration
+}
+
+// BackoffFunc adapts a function to a BackoffStrategy.
+type BackoffFunc func(attempt int, prev time.Duration) time.Duration
+
+func (f BackoffFunc) Backoff(attempt int, prev time.Duration) time.Duration { return f(attempt, prev) }
+
+// ConstantBackoff waits the same delay before every retry.
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+func (b ConstantBackoff) Backoff(attempt int, prev time.Duration) time.Duration {
+	return b.Delay
+}
+
+// ExponentialBackoff doubles the delay on every attempt, starting at Base and
+// capped at Max when set. Jitter randomizes each delay between half and all
+// of its value.
+type ExponentialBackoff struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter bool
+}
+
+func (b ExponentialBackoff) Backoff(attempt int, prev time.Duration) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempt && delay > 0; i++ {
+		delay *= 2
+		if b.Max > 0 && delay >= b.Max {
+			delay = b.Max
+			break
+		}
+	}
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	if b.Jitter && delay > 0 {
+		delay = delay/2 + rand.N(delay/2+1)
+	}
+	return delay
+}
+
+// DecorrelatedJitterBackoff picks a random delay between Base and three times
+// the previous delay, capped at Max when set. It spreads out retries of many
+// clients better than exponential backoff.
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (b DecorrelatedJitterBackoff) Backoff(attempt int, prev time.Duration) time.Duration {
+	if b.Base <= 0 {
+		return 0
+	}
+	upper := max(prev*3, b.Base)
+	delay := b.Base + rand.N(upper-b.Base+1)
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
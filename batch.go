@@ -0,0 +1,61 @@
+package quester
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchResult is the outcome of one request sent by Client.Batch.
+type BatchResult struct {
+	Response *Response
+	Err      error
+}
+
+// Batch sends reqs with at most concurrency requests in flight, zero or less
+// meaning all at once, and returns their results in input order. Response
+// bodies are kept, so they can be decoded with Response.Into.
+func (c *Client) Batch(reqs []*Request, concurrency int) []BatchResult {
+	return c.BatchContext(context.Background(), reqs, concurrency)
+}
+
+// BatchContext is like Batch, but stops scheduling requests once ctx is done;
+// requests that were not sent get ctx's error. In-flight requests are
+// cancelled with ctx as well, in addition to their own context.
+func (c *Client) BatchContext(ctx context.Context, reqs []*Request, concurrency int) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = len(reqs)
+	}
+	results := make([]BatchResult, len(reqs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, req := range reqs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(reqs); j++ {
+				results[j].Err = err
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			reqCtx, cancel := context.WithCancel(req.ctxOrDefault())
+			defer cancel()
+			stop := context.AfterFunc(ctx, cancel)
+			defer stop()
+
+			r := req.WithContext(reqCtx)
+			r.keepRawBody = true
+			results[i].Response, results[i].Err = r.Do(nil)
+		}()
+	}
+	wg.Wait()
+	return results
+}
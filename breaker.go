@@ -0,0 +1,119 @@
+package quester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Do while the circuit breaker for a host is open.
+var ErrCircuitOpen = errors.New("quester: circuit breaker is open")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuit struct {
+	state    circuitState
+	failures int
+	since    time.Time // when the circuit opened, or when the probe started
+}
+
+// CircuitBreakerHook opens the circuit for a host after Threshold consecutive
+// failures (transport errors or 5xx responses) and rejects requests to it with ErrCircuitOpen for
+// Cooldown. After the cooldown a single probe request is let through: success
+// closes the circuit, failure opens it again.
+type CircuitBreakerHook struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+// NewCircuitBreakerHook creates a circuit breaker hook.
+func NewCircuitBreakerHook(threshold int, cooldown time.Duration) *CircuitBreakerHook {
+	return &CircuitBreakerHook{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		hosts:     make(map[string]*circuit),
+	}
+}
+
+func (b *CircuitBreakerHook) circuit(host string) *circuit {
+	if b.hosts == nil {
+		b.hosts = make(map[string]*circuit)
+	}
+	c, ok := b.hosts[host]
+	if !ok {
+		c = &circuit{}
+		b.hosts[host] = c
+	}
+	return c
+}
+
+func (b *CircuitBreakerHook) PreRequest(req *http.Request) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(req.URL.Host)
+	switch c.state {
+	case circuitOpen, circuitHalfOpen:
+		// Only one probe at a time; a probe that never reported back is
+		// abandoned after another cooldown.
+		if time.Since(c.since) < b.Cooldown {
+			return ErrCircuitOpen
+		}
+		c.state = circuitHalfOpen
+		c.since = time.Now()
+	}
+	return nil
+}
+
+func (b *CircuitBreakerHook) PostResponse(res *http.Response, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	host, ok := requestHost(res, err)
+	if !ok {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	if err == nil && res.StatusCode < 500 {
+		c.state = circuitClosed
+		c.failures = 0
+		return nil
+	}
+
+	c.failures++
+	if c.state == circuitHalfOpen || c.failures >= b.Threshold {
+		c.state = circuitOpen
+		c.since = time.Now()
+	}
+	return nil
+}
+
+// requestHost returns the host a response or transport error belongs to.
+func requestHost(res *http.Response, err error) (string, bool) {
+	if res != nil && res.Request != nil {
+		return res.Request.URL.Host, true
+	}
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		if u, perr := url.Parse(uerr.URL); perr == nil {
+			return u.Host, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,196 @@
+package quester
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheHitKey marks the request of responses served by CacheHook. A context
+// value cannot be forged by the server, unlike a header.
+type cacheHitKey struct{}
+
+// CacheHook is an in-memory LRU response cache. Responses are stored keyed
+// by method and URL when they carry Cache-Control max-age, and served without
+// a network call while fresh. no-store on the request or response disables
+// caching. Vary is not taken into account.
+type CacheHook struct {
+	// MaxEntries bounds the number of cached responses. Zero means no limit.
+	MaxEntries int
+	// Methods lists the cacheable methods, GET and HEAD when empty.
+	Methods []string
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	status  int
+	proto   string
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// NewCacheHook creates a cache holding at most maxEntries responses.
+func NewCacheHook(maxEntries int) *CacheHook {
+	return &CacheHook{MaxEntries: maxEntries}
+}
+
+func (c *CacheHook) PreRequest(req *http.Request) error {
+	return nil
+}
+
+// Intercept serves a fresh cached response for req, if any.
+func (c *CacheHook) Intercept(req *http.Request) (*http.Response, error) {
+	if !c.cacheable(req) {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[cacheKey(req)]
+	if !ok {
+		return nil, nil
+	}
+	e := el.Value.(*cacheEntry)
+	if time.Now().After(e.expires) {
+		c.remove(el)
+		return nil, nil
+	}
+	c.ll.MoveToFront(el)
+
+	return &http.Response{
+		Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         e.proto,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req.WithContext(context.WithValue(req.Context(), cacheHitKey{}, true)),
+	}, nil
+}
+
+// PostResponse stores cacheable responses.
+func (c *CacheHook) PostResponse(res *http.Response, err error) error {
+	if err != nil || res == nil || res.Request == nil || fromCache(res) {
+		return nil
+	}
+	if !c.cacheable(res.Request) || res.StatusCode != http.StatusOK {
+		return nil
+	}
+	maxAge, ok := cacheMaxAge(res.Header.Get("Cache-Control"))
+	if !ok {
+		return nil
+	}
+
+	// Buffer the body so it can be both cached and read by the caller
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.ll = list.New()
+		c.entries = make(map[string]*list.Element)
+	}
+	e := &cacheEntry{
+		key:     cacheKey(res.Request),
+		status:  res.StatusCode,
+		proto:   res.Proto,
+		header:  res.Header.Clone(),
+		body:    body,
+		expires: time.Now().Add(maxAge),
+	}
+	if el, ok := c.entries[e.key]; ok {
+		el.Value = e
+		c.ll.MoveToFront(el)
+	} else {
+		c.entries[e.key] = c.ll.PushFront(e)
+	}
+	for c.MaxEntries > 0 && c.ll.Len() > c.MaxEntries {
+		c.remove(c.ll.Back())
+	}
+	return nil
+}
+
+// Purge removes all cached responses.
+func (c *CacheHook) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll = nil
+	c.entries = nil
+}
+
+func (c *CacheHook) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
+
+func (c *CacheHook) cacheable(req *http.Request) bool {
+	methods := c.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if !slices.Contains(methods, req.Method) {
+		return false
+	}
+	return !hasDirective(req.Header.Get("Cache-Control"), "no-store")
+}
+
+// fromCache reports whether res was served by a CacheHook.
+func fromCache(res *http.Response) bool {
+	if res.Request == nil {
+		return false
+	}
+	hit, _ := res.Request.Context().Value(cacheHitKey{}).(bool)
+	return hit
+}
+
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String()
+}
+
+// cacheMaxAge returns the max-age of a Cache-Control header, unless the
+// response must not be stored.
+func cacheMaxAge(cacheControl string) (time.Duration, bool) {
+	if hasDirective(cacheControl, "no-store") {
+		return 0, false
+	}
+	for _, d := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, "max-age") {
+			secs, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || secs <= 0 {
+				return 0, false
+			}
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+func hasDirective(cacheControl, directive string) bool {
+	for _, d := range strings.Split(cacheControl, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package quester
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// utf8Body is a response body transcoded to UTF-8 from the charset of its
+// Content-Type. It implements io.ByteReader, so the XML decoder reads it
+// directly and can tell it was already transcoded.
+type utf8Body struct {
+	*bufio.Reader
+}
+
+// transcode converts body to UTF-8 according to the charset parameter of
+// contentType. Bodies without a charset are assumed to be UTF-8.
+func transcode(contentType string, body io.Reader) (io.Reader, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body, nil
+	}
+	charset := strings.ToLower(params["charset"])
+	if charset == "" || charset == "utf-8" || charset == "utf8" {
+		return body, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("quester: unsupported charset %q", charset)
+	}
+	return utf8Body{bufio.NewReader(transform.NewReader(body, enc.NewDecoder()))}, nil
+}
+
+// xmlCharsetReader decodes XML documents declaring a non-UTF-8 encoding.
+func xmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	if _, ok := input.(utf8Body); ok {
+		return input, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("quester: unsupported charset %q", charset)
+	}
+	return transform.NewReader(input, enc.NewDecoder()), nil
+}
@@ -1,7 +1,14 @@
 package quester
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,20 +18,37 @@ type Client struct {
 	Timeout   time.Duration
 	client    *http.Client
 	hooks     []Hooks
+	retry     *RetryConfig
+	limiter   *rateLimiter
+	encoders  map[string]Encoder
+	decoders  map[string]Decoder
+	query     url.Values
+	forced    http.Header
 	UserAgent string
+
+	maxRequestBytes  int64
+	maxResponseBytes int64
+	rewriteURL       func(*url.URL) *url.URL
+	sensitive        []string
+	strict           bool
+	defaultBody      func() any
+	closed           atomic.Bool
+	errorHandler     func(*Response) error
+	ownTransport     *http.Transport
 }
 
+const defaultUserAgent = "go.blk/httpclient"
+
 // NewClient creates a new HTTP client with base URL.
 func NewClient(baseURL string) *Client {
 	return &Client{
-		BaseURL: baseURL,
+		BaseURL:   baseURL,
+		UserAgent: defaultUserAgent,
 		Headers: http.Header{
-			"User-Agent": []string{"go.blk/httpclient"},
+			"User-Agent": []string{defaultUserAgent},
 		},
 		Timeout: 30 * time.Second,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  &http.Client{},
 	}
 }
 
@@ -33,12 +57,138 @@ func (c *Client) R() *Request {
 	return &Request{
 		client:  c,
 		headers: http.Header{},
-		query:   make(map[string]string),
+		query:   url.Values{},
+	}
+}
+
+// SetDefaultQuery sets a query parameter sent with every request. Values set
+// on a request take precedence.
+func (c *Client) SetDefaultQuery(key, value string) {
+	if c.query == nil {
+		c.query = url.Values{}
+	}
+	c.query.Set(key, value)
+}
+
+// SetDefaultQueries sets several default query parameters.
+func (c *Client) SetDefaultQueries(queries map[string]string) {
+	for k, v := range queries {
+		c.SetDefaultQuery(k, v)
+	}
+}
+
+// SetDefaultBody sets a function returning the body of POST, PUT and PATCH
+// requests that do not set one, e.g. a standard envelope. It is called for
+// every such request; a body set via SetBody replaces the default entirely.
+func (c *Client) SetDefaultBody(fn func() any) {
+	c.defaultBody = fn
+}
+
+// SetErrorHandler sets a function called by Request.Do with every response
+// after it was decoded. A non-nil error is returned by Do, e.g. to turn 4xx
+// and 5xx responses into errors for a whole service. It is not called when
+// Do already failed, or for requests using SkipErrorHandler.
+func (c *Client) SetErrorHandler(fn func(*Response) error) {
+	c.errorHandler = fn
+}
+
+// SetURLRewriter sets a function that may rewrite the final URL of every
+// request, after the base URL, path and query were combined. Returning nil
+// keeps the URL unchanged.
+func (c *Client) SetURLRewriter(fn func(*url.URL) *url.URL) {
+	c.rewriteURL = fn
+}
+
+// SetTimeout sets the timeout of every request sent by the client, including
+// reading the response body. Zero means no timeout. It is equivalent to
+// assigning the Timeout field.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.Timeout = d
+}
+
+// SetUserAgent sets the User-Agent sent when a request does not set one.
+func (c *Client) SetUserAgent(s string) {
+	c.UserAgent = s
+	if s == "" {
+		c.Headers.Del("User-Agent")
+		return
+	}
+	if c.Headers == nil {
+		c.Headers = http.Header{}
 	}
+	c.Headers.Set("User-Agent", s)
+}
+
+// SetForcedHeader sets a header that overwrites any value set on a request.
+//
+// Header precedence, from highest to lowest: forced headers, per-request
+// headers, then the default Headers, which only fill in missing values.
+func (c *Client) SetForcedHeader(key, value string) {
+	if c.forced == nil {
+		c.forced = http.Header{}
+	}
+	c.forced.Set(key, value)
+}
+
+// Get starts a GET request for path.
+func (c *Client) Get(path string) *Request {
+	return c.R().SetMethod(http.MethodGet).SetPath(path)
+}
+
+// Head starts a HEAD request for path. The response body is never decoded.
+func (c *Client) Head(path string) *Request {
+	return c.R().SetMethod(http.MethodHead).SetPath(path)
+}
+
+// Post starts a POST request for path with body.
+func (c *Client) Post(path string, body any) *Request {
+	return c.R().SetMethod(http.MethodPost).SetPath(path).SetBody(body)
+}
+
+// Put starts a PUT request for path with body.
+func (c *Client) Put(path string, body any) *Request {
+	return c.R().SetMethod(http.MethodPut).SetPath(path).SetBody(body)
+}
+
+// Patch starts a PATCH request for path with body.
+func (c *Client) Patch(path string, body any) *Request {
+	return c.R().SetMethod(http.MethodPatch).SetPath(path).SetBody(body)
+}
+
+// Delete starts a DELETE request for path.
+func (c *Client) Delete(path string) *Request {
+	return c.R().SetMethod(http.MethodDelete).SetPath(path)
 }
 
 // Do is used internally to execute request, called by Request.Do().
-func (c *Client) Do(req *http.Request) (*http.Response, error) {
+func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
+	if c.closed.Load() {
+		closeRequestBody(req)
+		return nil, ErrClientClosed
+	}
+
+	// Requests built with R() carry the timeout in their context already
+	ctx := req.Context()
+	if _, ok := ctx.Deadline(); !ok && c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer func() {
+			if err != nil || resp == nil {
+				cancel()
+				return
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+		}()
+	}
+
+	// Let hooks look up headers to redact
+	req = req.WithContext(context.WithValue(ctx, sensitiveKey{}, c.SensitiveHeaders()))
+
+	// UserAgent applies unless the request sets its own
+	if c.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
 	// Apply default headers
 	for k, vals := range c.Headers {
 		for _, v := range vals {
@@ -48,25 +198,201 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		}
 	}
 
+	// Apply forced headers
+	for k, vals := range c.forced {
+		req.Header[k] = append([]string(nil), vals...)
+	}
+
+	hooks := c.hooks
+	if extra, ok := req.Context().Value(requestHooksKey{}).([]Hooks); ok {
+		hooks = append(slices.Clip(hooks), extra...)
+	}
+
+	start := time.Now()
+	defer func() {
+		d := time.Since(start)
+		for _, h := range hooks {
+			if o, ok := h.(Observer); ok {
+				o.Observe(req, resp, err, d)
+			}
+		}
+	}()
+
+	resp, err = c.runHooks(req, hooks)
+
+	// Let a replayer resend the request once, e.g. after refreshing credentials
+	if shouldReplay(hooks, resp, err) && canReplay(req) {
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr != nil {
+				return nil, berr
+			}
+			req.Body = body
+		}
+		resp, err = c.runHooks(req, hooks)
+	}
+
+	return resp, err
+}
+
+// runHooks sends req through the hook pipeline.
+func (c *Client) runHooks(req *http.Request, hooks []Hooks) (*http.Response, error) {
+	inspect := inspectsBody(hooks)
+	if inspect {
+		if _, err := bufferBody(req); err != nil {
+			return nil, err
+		}
+	}
+
 	// Call Pre hooks
-	for _, h := range c.hooks {
+	for _, h := range hooks {
 		if err := h.PreRequest(req); err != nil {
 			return nil, err
 		}
+		// Hand the next hook a fresh body in case this one read req.Body
+		if inspect {
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Let interceptors short-circuit the network call
+	var resp *http.Response
+	var err error
+	for _, h := range hooks {
+		if i, ok := h.(Interceptor); ok {
+			if resp, err = i.Intercept(req); resp != nil || err != nil {
+				break
+			}
+		}
 	}
 
 	// Do request
-	resp, err := c.client.Do(req)
+	if resp == nil && err == nil {
+		resp, err = c.send(req)
+	}
 
-	// Call Post hooks
-	for _, h := range c.hooks {
-		_ = h.PostResponse(resp)
+	// Call Post hooks, the first hook error replaces the request error
+	var hookErr error
+	for _, h := range hooks {
+		if herr := h.PostResponse(resp, err); herr != nil && hookErr == nil {
+			hookErr = herr
+		}
+	}
+	if hookErr != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, hookErr
 	}
 
 	return resp, err
 }
 
+func inspectsBody(hooks []Hooks) bool {
+	for _, h := range hooks {
+		if b, ok := h.(BodyInspector); ok && b.InspectsBody() {
+			return true
+		}
+	}
+	return false
+}
+
+func shouldReplay(hooks []Hooks, resp *http.Response, err error) bool {
+	for _, h := range hooks {
+		if r, ok := h.(Replayer); ok && r.Replay(resp, err) {
+			return true
+		}
+	}
+	return false
+}
+
+// roundTrip sends a single attempt, waiting on the rate limit first.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	if c.limiter != nil {
+		if err := c.limiter.wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	if n, ok := req.Context().Value(attemptsKey{}).(*atomic.Int64); ok {
+		n.Add(1)
+	}
+
+	d := debuggerFrom(req.Context())
+	if d != nil {
+		d.dumpRequest(req)
+	}
+	res, err := c.client.Do(req)
+	if d != nil {
+		d.dumpResponse(res, err)
+	}
+	return res, err
+}
+
+// Close releases the idle connections of the underlying transport. Requests
+// sent after Close fail with ErrClientClosed.
+func (c *Client) Close() {
+	c.closed.Store(true)
+	c.client.CloseIdleConnections()
+}
+
 // Use adds middleware hook (logging, retry, etc).
 func (c *Client) Use(h Hooks) {
 	c.hooks = append(c.hooks, h)
 }
+
+// SetCookieJar sets the cookie jar used to store and send cookies.
+func (c *Client) SetCookieJar(jar http.CookieJar) {
+	c.client.Jar = jar
+}
+
+// EnableCookies installs an in-memory cookie jar, so cookies set by responses
+// are sent on subsequent requests to the same host.
+func (c *Client) EnableCookies() {
+	jar, _ := cookiejar.New(nil) // never fails without options
+	c.SetCookieJar(jar)
+}
+
+// SetRedirectPolicy sets the policy used to decide whether to follow redirects.
+func (c *Client) SetRedirectPolicy(fn func(req *http.Request, via []*http.Request) error) {
+	c.client.CheckRedirect = fn
+}
+
+// DisableRedirects stops following redirects; Do returns the 3xx response as is.
+func (c *Client) DisableRedirects() {
+	c.SetRedirectPolicy(func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+}
+
+// SetMaxRedirects follows at most n redirects before returning an error.
+func (c *Client) SetMaxRedirects(n int) {
+	c.SetRedirectPolicy(func(req *http.Request, via []*http.Request) error {
+		if len(via) > n {
+			return fmt.Errorf("quester: stopped after %d redirects", n)
+		}
+		return nil
+	})
+}
+
+// SetHTTPClient replaces the underlying *http.Client, e.g. to share a pooled
+// transport. The Timeout field is updated to match hc.Timeout. hc is copied,
+// so later changes to either timeout do not affect the other.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	cp := *hc
+	c.Timeout = cp.Timeout
+	// The timeout is enforced through the request context only
+	cp.Timeout = 0
+	c.client = &cp
+}
+
+// SetTransport sets the RoundTripper used by the underlying *http.Client.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.client.Transport = rt
+}
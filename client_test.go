@@ -0,0 +1,108 @@
+package quester
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func slowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientTimeoutField(t *testing.T) {
+	srv := slowServer(t, 100*time.Millisecond)
+	c := NewClient(srv.URL)
+
+	c.Timeout = 20 * time.Millisecond
+	if _, err := c.Get("/").Do(nil); !IsTimeout(err) {
+		t.Fatalf("lowered timeout: got %v, want timeout", err)
+	}
+
+	c.Timeout = 5 * time.Second
+	if _, err := c.Get("/").Do(nil); err != nil {
+		t.Fatalf("raised timeout: %v", err)
+	}
+}
+
+func TestClientSetTimeout(t *testing.T) {
+	srv := slowServer(t, 100*time.Millisecond)
+	c := NewClient(srv.URL)
+
+	c.SetTimeout(20 * time.Millisecond)
+	if _, err := c.Get("/").Do(nil); !IsTimeout(err) {
+		t.Fatalf("SetTimeout: got %v, want timeout", err)
+	}
+
+	// Raising the field after SetTimeout must not leave a stale limit behind
+	c.Timeout = 5 * time.Second
+	if _, err := c.Get("/").Do(nil); err != nil {
+		t.Fatalf("raised timeout: %v", err)
+	}
+
+	c.SetTimeout(0)
+	if _, err := c.Get("/").Do(nil); err != nil {
+		t.Fatalf("no timeout: %v", err)
+	}
+}
+
+func TestClientTimeoutDeadline(t *testing.T) {
+	c := NewClient("http://example.com")
+	for _, d := range []time.Duration{time.Second, time.Hour} {
+		c.Timeout = d
+		deadline, ok := c.R().effectiveDeadline()
+		if !ok {
+			t.Fatalf("Timeout %v: no deadline", d)
+		}
+		if left := time.Until(deadline); left > d || left < d-time.Second {
+			t.Fatalf("Timeout %v: deadline in %v", d, left)
+		}
+	}
+
+	c.Timeout = 0
+	if _, ok := c.R().effectiveDeadline(); ok {
+		t.Fatal("zero Timeout: unexpected deadline")
+	}
+}
+
+func TestClientDoTimeout(t *testing.T) {
+	srv := slowServer(t, 100*time.Millisecond)
+	c := NewClient(srv.URL)
+	c.Timeout = 20 * time.Millisecond
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Do(req); !IsTimeout(err) {
+		t.Fatalf("got %v, want timeout", err)
+	}
+}
+
+func TestSetHTTPClientTimeout(t *testing.T) {
+	srv := slowServer(t, 100*time.Millisecond)
+	c := NewClient(srv.URL)
+	hc := &http.Client{Timeout: 20 * time.Millisecond}
+	c.SetHTTPClient(hc)
+	if c.Timeout != 20*time.Millisecond {
+		t.Fatalf("Timeout = %v", c.Timeout)
+	}
+
+	c.Timeout = 5 * time.Second
+	if _, err := c.Get("/").Do(nil); err != nil {
+		t.Fatalf("raised timeout: %v", err)
+	}
+	if hc.Timeout != 20*time.Millisecond {
+		t.Fatalf("caller's client modified: Timeout = %v", hc.Timeout)
+	}
+}
@@ -0,0 +1,154 @@
+package quester
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Encoder encodes request bodies for a content type.
+type Encoder interface {
+	Encode(w io.Writer, v any) error
+}
+
+// Decoder decodes response bodies for a content type.
+type Decoder interface {
+	Decode(r io.Reader, v any) error
+}
+
+// EncoderFunc adapts a function to an Encoder.
+type EncoderFunc func(w io.Writer, v any) error
+
+func (f EncoderFunc) Encode(w io.Writer, v any) error { return f(w, v) }
+
+// DecoderFunc adapts a function to a Decoder.
+type DecoderFunc func(r io.Reader, v any) error
+
+func (f DecoderFunc) Decode(r io.Reader, v any) error { return f(r, v) }
+
+var (
+	jsonEncoder = EncoderFunc(func(w io.Writer, v any) error { return json.NewEncoder(w).Encode(v) })
+	xmlEncoder  = EncoderFunc(func(w io.Writer, v any) error { return xml.NewEncoder(w).Encode(v) })
+	jsonDecoder = DecoderFunc(func(r io.Reader, v any) error { return json.NewDecoder(r).Decode(v) })
+	xmlDecoder  = DecoderFunc(func(r io.Reader, v any) error {
+		d := xml.NewDecoder(r)
+		d.CharsetReader = xmlCharsetReader
+		return d.Decode(v)
+	})
+)
+
+// Built-in codecs, used unless overridden on the client.
+var (
+	defaultEncoders = map[string]Encoder{
+		"application/json": jsonEncoder,
+		"application/xml":  xmlEncoder,
+		"text/xml":         xmlEncoder,
+	}
+	defaultDecoders = map[string]Decoder{
+		"application/json": jsonDecoder,
+		"application/xml":  xmlDecoder,
+		"text/xml":         xmlDecoder,
+	}
+)
+
+// RegisterEncoder registers enc for request bodies with the given content type.
+func (c *Client) RegisterEncoder(contentType string, enc Encoder) {
+	if c.encoders == nil {
+		c.encoders = make(map[string]Encoder)
+	}
+	c.encoders[mediaType(contentType)] = enc
+}
+
+// RegisterDecoder registers dec for responses with the given content type.
+func (c *Client) RegisterDecoder(contentType string, dec Decoder) {
+	if c.decoders == nil {
+		c.decoders = make(map[string]Decoder)
+	}
+	c.decoders[mediaType(contentType)] = dec
+}
+
+// SetJSONEncoderConfig configures how JSON request bodies are encoded:
+// escapeHTML escapes &, < and > as json.Encoder does by default, and a
+// non-empty indent pretty-prints bodies. It replaces any encoder registered
+// for application/json.
+func (c *Client) SetJSONEncoderConfig(escapeHTML bool, indent string) {
+	c.RegisterEncoder("application/json", EncoderFunc(func(w io.Writer, v any) error {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(escapeHTML)
+		enc.SetIndent("", indent)
+		return enc.Encode(v)
+	}))
+}
+
+func (c *Client) encoder(contentType string) (Encoder, bool) {
+	for _, mt := range mediaTypes(contentType) {
+		if c != nil {
+			if enc, ok := c.encoders[mt]; ok {
+				return enc, true
+			}
+		}
+		if enc, ok := defaultEncoders[mt]; ok {
+			return enc, true
+		}
+	}
+	return nil, false
+}
+
+func (c *Client) decoder(contentType string) (Decoder, bool) {
+	for _, mt := range mediaTypes(contentType) {
+		if c != nil {
+			if dec, ok := c.decoders[mt]; ok {
+				return dec, true
+			}
+		}
+		if dec, ok := defaultDecoders[mt]; ok {
+			return dec, true
+		}
+	}
+	return nil, false
+}
+
+// decode decodes body into result with the decoder registered for
+// contentType. It reports false when there is no such decoder.
+func (c *Client) decode(contentType string, body io.Reader, result any) (bool, error) {
+	dec, ok := c.decoder(contentType)
+	if !ok {
+		return false, nil
+	}
+	body, err := transcode(contentType, body)
+	if err != nil {
+		return true, err
+	}
+	return true, dec.Decode(body, result)
+}
+
+// mediaType strips parameters from a content type and lowercases it.
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	mt, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// mediaTypes returns the media type of contentType to look codecs up with,
+// followed by the generic type of a structured syntax suffix, e.g.
+// application/json for application/vnd.api+json.
+func mediaTypes(contentType string) []string {
+	mt := mediaType(contentType)
+	switch {
+	case strings.HasSuffix(mt, "+json"):
+		return []string{mt, "application/json"}
+	case strings.HasSuffix(mt, "+xml"):
+		return []string{mt, "application/xml"}
+	}
+	return []string{mt}
+}
+
+// isJSON reports whether contentType is JSON, including +json vendor types.
+func isJSON(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
@@ -0,0 +1,107 @@
+package quester
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SetCompression compresses encoded request bodies with algo ("gzip" or
+// "deflate") and sets Content-Encoding. The whole compressed body is buffered
+// in memory, so peak usage is roughly the encoded plus compressed size.
+// io.Reader bodies are streamed and never compressed; wrap them yourself.
+func (r *Request) SetCompression(algo string) *Request {
+	r.compression = strings.ToLower(algo)
+	return r
+}
+
+// DisableDecompression leaves gzip and deflate encoded response bodies as is.
+// Unless an Accept-Encoding header is set, "gzip, deflate" is requested.
+func (r *Request) DisableDecompression() *Request {
+	r.disableDecompression = true
+	return r
+}
+
+type decompressedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	var err error
+	for _, c := range b.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// decompress replaces a gzip or deflate encoded res.Body with a decoding
+// reader and strips the encoding headers.
+func decompress(res *http.Response) error {
+	if bodyless(res) {
+		return nil
+	}
+
+	var (
+		rc  io.ReadCloser
+		err error
+	)
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		rc, err = gzip.NewReader(res.Body)
+	case "deflate":
+		rc, err = newDeflateReader(res.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	res.Body = &decompressedBody{Reader: rc, closers: []io.Closer{rc, res.Body}}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return nil
+}
+
+// newDeflateReader handles both zlib-wrapped (per RFC 9110) and raw deflate
+// streams, since servers disagree on what "deflate" means.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// compressBody compresses body into a buffer using algo.
+func compressBody(algo string, body io.Reader) (*bytes.Buffer, error) {
+	buf := &bytes.Buffer{}
+	var w io.WriteCloser
+	switch algo {
+	case "gzip":
+		w = gzip.NewWriter(buf)
+	case "deflate":
+		w = zlib.NewWriter(buf)
+	default:
+		return nil, fmt.Errorf("quester: unsupported compression %q", algo)
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
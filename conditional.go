@@ -0,0 +1,26 @@
+package quester
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IfNoneMatch sets the If-None-Match header. Unquoted tags are quoted.
+func (r *Request) IfNoneMatch(etag string) *Request {
+	if etag != "*" && !strings.HasPrefix(etag, `"`) && !strings.HasPrefix(etag, `W/"`) {
+		etag = `"` + etag + `"`
+	}
+	return r.SetHeader("If-None-Match", etag)
+}
+
+// IfModifiedSince sets the If-Modified-Since header.
+func (r *Request) IfModifiedSince(t time.Time) *Request {
+	return r.SetHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// NotModified reports whether the server answered a conditional request with
+// 304 Not Modified. The body is never decoded in that case.
+func (r *Response) NotModified() bool {
+	return r.Status == http.StatusNotModified
+}
@@ -0,0 +1,39 @@
+package quester
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultDeadlineHeader is the header used by PropagateDeadline and
+// DeadlineContext when none is given.
+const DefaultDeadlineHeader = "X-Request-Deadline"
+
+// PropagateDeadline sends the deadline of the request context, including the
+// request and client timeouts, in the given header as Unix milliseconds, so
+// downstream services can stop working on requests that the caller gave up
+// on. An empty header name uses DefaultDeadlineHeader. Nothing is sent when
+// the request has no deadline.
+func (r *Request) PropagateDeadline(header string) *Request {
+	if header == "" {
+		header = DefaultDeadlineHeader
+	}
+	r.deadlineHeader = header
+	return r
+}
+
+// DeadlineContext derives a context from req that expires at the deadline
+// sent by PropagateDeadline in header, for servers receiving such requests.
+// Without a valid header the context only ends with the request context.
+func DeadlineContext(req *http.Request, header string) (context.Context, context.CancelFunc) {
+	if header == "" {
+		header = DefaultDeadlineHeader
+	}
+	ms, err := strconv.ParseInt(req.Header.Get(header), 10, 64)
+	if err != nil {
+		return context.WithCancel(req.Context())
+	}
+	return context.WithDeadline(req.Context(), time.UnixMilli(ms))
+}
@@ -0,0 +1,83 @@
+package quester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+)
+
+type debugKey struct{}
+
+// debugger dumps the wire format of requests and responses.
+type debugger struct {
+	w       io.Writer
+	secrets bool
+}
+
+// Debug writes the outgoing request and the received response, as sent on
+// the wire, to w. The client's sensitive headers are redacted. The response
+// body is buffered in memory to be dumped.
+func (r *Request) Debug(w io.Writer) *Request {
+	r.debug = &debugger{w: w}
+	return r
+}
+
+// DebugWithSecrets is like Debug but does not redact credentials. Only use it
+// locally.
+func (r *Request) DebugWithSecrets(w io.Writer) *Request {
+	r.debug = &debugger{w: w, secrets: true}
+	return r
+}
+
+func debuggerFrom(ctx context.Context) *debugger {
+	d, _ := ctx.Value(debugKey{}).(*debugger)
+	return d
+}
+
+func (d *debugger) dumpRequest(req *http.Request) {
+	out := req.Clone(req.Context())
+	if !d.secrets {
+		out.Header = RedactHeaders(req.Header, SensitiveHeaders(req.Context()))
+	}
+
+	// Only dump bodies that can be read again without consuming the request
+	withBody := false
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			out.Body = body
+			withBody = true
+		}
+	}
+	if !withBody {
+		out.Body = nil
+	}
+
+	dump, err := httputil.DumpRequestOut(out, withBody)
+	if err != nil {
+		fmt.Fprintf(d.w, "[DEBUG] dump request: %v\n", err)
+		return
+	}
+	fmt.Fprintf(d.w, "[DEBUG] Request\n%s\n", dump)
+}
+
+func (d *debugger) dumpResponse(res *http.Response, err error) {
+	if err != nil {
+		fmt.Fprintf(d.w, "[DEBUG] Error: %v\n", err)
+		return
+	}
+	// DumpResponse buffers and replaces the body, so dump a copy with
+	// redacted headers that shares the body field
+	out := *res
+	if !d.secrets && res.Request != nil {
+		out.Header = RedactHeaders(res.Header, SensitiveHeaders(res.Request.Context()))
+	}
+	dump, derr := httputil.DumpResponse(&out, true)
+	res.Body = out.Body
+	if derr != nil {
+		fmt.Fprintf(d.w, "[DEBUG] dump response: %v\n", derr)
+		return
+	}
+	fmt.Fprintf(d.w, "[DEBUG] Response\n%s\n", dump)
+}
@@ -0,0 +1,6 @@
+// Package quester is a small fluent HTTP client built on net/http.
+//
+//	c := quester.NewClient("https://api.example.com")
+//	var user User
+//	resp, err := c.R().SetMethod("GET").SetPath("/users/42").Do(&user)
+package quester
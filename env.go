@@ -0,0 +1,46 @@
+package quester
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// NewClientFromEnv creates a client configured from environment variables
+// named after prefix, e.g. for prefix "API":
+//
+//	API_BASE_URL              base URL
+//	API_TIMEOUT               request timeout, e.g. "10s"; "0" disables it
+//	API_PROXY                 proxy URL
+//	API_INSECURE_SKIP_VERIFY  "true" disables TLS certificate verification
+//
+// Unset variables keep the defaults of NewClient. Malformed values are
+// reported as errors.
+func NewClientFromEnv(prefix string) (*Client, error) {
+	if prefix != "" {
+		prefix += "_"
+	}
+	c := NewClient(os.Getenv(prefix + "BASE_URL"))
+
+	if v := os.Getenv(prefix + "TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("quester: invalid %sTIMEOUT: %w", prefix, err)
+		}
+		c.SetTimeout(d)
+	}
+	if v := os.Getenv(prefix + "PROXY"); v != "" {
+		if err := c.SetProxy(v); err != nil {
+			return nil, err
+		}
+	}
+	if v := os.Getenv(prefix + "INSECURE_SKIP_VERIFY"); v != "" {
+		skip, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("quester: invalid %sINSECURE_SKIP_VERIFY: %w", prefix, err)
+		}
+		c.InsecureSkipVerify(skip)
+	}
+	return c, nil
+}
@@ -0,0 +1,155 @@
+package quester
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"syscall"
+)
+
+// ErrConflictingAuth is returned by Do when a request sets both basic auth and
+// a bearer token.
+var ErrConflictingAuth = errors.New("quester: both basic auth and bearer token are set")
+
+// ErrClientClosed is returned when sending a request with a closed Client.
+var ErrClientClosed = errors.New("quester: client is closed")
+
+// HTTPError is returned by Do when the response status is not accepted.
+type HTTPError struct {
+	Status     int
+	StatusText string
+	Body       []byte
+	Response   *Response
+	// Result holds the decoded error body when SetErrorResult was used.
+	Result any
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("quester: unexpected response status %s", e.StatusText)
+}
+
+// Unwrap returns Result when the decoded error body is itself an error.
+func (e *HTTPError) Unwrap() error {
+	err, _ := e.Result.(error)
+	return err
+}
+
+// BodyTooLargeError is returned when a request or response body exceeds the
+// limit configured on the client.
+type BodyTooLargeError struct {
+	Limit    int64
+	Response bool
+}
+
+func (e *BodyTooLargeError) Error() string {
+	kind := "request"
+	if e.Response {
+		kind = "response"
+	}
+	return fmt.Sprintf("quester: %s body exceeds %d bytes", kind, e.Limit)
+}
+
+// decodeSnippetLen is the number of body bytes kept in a DecodeError.
+const decodeSnippetLen = 256
+
+// DecodeError is returned by Do when the response body could not be decoded.
+type DecodeError struct {
+	ContentType string
+	// Snippet holds the start of the body, up to 256 bytes.
+	Snippet string
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("quester: decoding %q response: %v", e.ContentType, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// captureReader keeps the start of the data read and the read error, to
+// tell decode errors from transport errors.
+type captureReader struct {
+	r       io.Reader
+	head    []byte
+	readErr error
+}
+
+func (c *captureReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if room := decodeSnippetLen - len(c.head); room > 0 {
+		c.head = append(c.head, p[:min(n, room)]...)
+	}
+	if err != nil && err != io.EOF {
+		c.readErr = err
+	}
+	return n, err
+}
+
+// IsTimeout reports whether err is caused by a timeout or an expired
+// deadline.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectionError reports whether err happened while connecting to or
+// talking with the server, e.g. a DNS failure, a refused or reset
+// connection.
+func IsConnectionError(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// ExpectStatus makes Do return an *HTTPError when the response status is not
+// one of codes. Without codes, any 2xx status is accepted.
+func (r *Request) ExpectStatus(codes ...int) *Request {
+	r.checkStatus = true
+	r.expectStatus = codes
+	return r
+}
+
+// SetErrorResult decodes non-2xx response bodies into v instead of the result
+// passed to Do. Do then returns an *HTTPError with Result set to v.
+func (r *Request) SetErrorResult(v any) *Request {
+	r.errorResult = v
+	return r
+}
+
+// statusError reads the body of a rejected response into an *HTTPError.
+func (r *Request) statusError(resp *Response, res *http.Response, contentType string) *HTTPError {
+	body, _ := io.ReadAll(res.Body)
+	resp.Body = body
+	resp.raw = body
+
+	httpErr := &HTTPError{
+		Status:     res.StatusCode,
+		StatusText: res.Status,
+		Body:       body,
+		Response:   resp,
+	}
+	if r.errorResult != nil && len(body) > 0 {
+		if ok, err := r.client.decode(contentType, bytes.NewReader(body), r.errorResult); ok && err == nil {
+			httpErr.Result = r.errorResult
+		}
+	}
+	return httpErr
+}
+
+func (r *Request) statusAccepted(status int) bool {
+	if len(r.expectStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(r.expectStatus, status)
+}
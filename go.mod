@@ -1,3 +1,5 @@
 module github.com/godev90/quester
 
-go 1.23.4
+go 1.24
+
+require golang.org/x/text v0.25.0
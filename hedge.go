@@ -0,0 +1,130 @@
+package quester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// EnableHedging sends a second identical request when no response arrived
+// after delay, and another one after each further delay, up to maxParallel
+// attempts in flight. The first successful response wins and the other
+// attempts are cancelled. Hedging only applies to idempotent methods with a
+// replayable body; other requests are sent once.
+func (r *Request) EnableHedging(delay time.Duration, maxParallel int) *Request {
+	r.hedgeDelay = delay
+	r.hedgeParallel = maxParallel
+	return r
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// do sends req through the client, hedging it when enabled.
+func (r *Request) do(req *http.Request) (*http.Response, error) {
+	if r.hedgeParallel < 2 || !idempotent(req.Method) || !canReplay(req) {
+		return r.client.Do(req)
+	}
+	return r.doHedged(req)
+}
+
+type hedgeResult struct {
+	index int
+	res   *http.Response
+	err   error
+}
+
+// cancelBody cancels the context of a request on Close.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (r *Request) doHedged(req *http.Request) (*http.Response, error) {
+	results := make(chan hedgeResult, r.hedgeParallel)
+	cancels := make([]context.CancelFunc, 0, r.hedgeParallel)
+
+	launch := func() error {
+		ctx, cancel := context.WithCancel(req.Context())
+		attempt := req.Clone(ctx)
+		if len(cancels) > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return err
+			}
+			attempt.Body = body
+		}
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			res, err := r.client.Do(attempt)
+			results <- hedgeResult{index: i, res: res, err: err}
+		}()
+		return nil
+	}
+
+	if err := launch(); err != nil {
+		return nil, err
+	}
+	pending := 1
+
+	timer := time.NewTimer(r.hedgeDelay)
+	defer timer.Stop()
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				for i, cancel := range cancels {
+					if i != result.index {
+						cancel()
+					}
+				}
+				go discardHedged(results, pending)
+				result.res.Body = &cancelBody{ReadCloser: result.res.Body, cancel: cancels[result.index]}
+				return result.res, nil
+			}
+			cancels[result.index]()
+			lastErr = result.err
+
+			// Replace a failed attempt right away
+			if len(cancels) < r.hedgeParallel && req.Context().Err() == nil {
+				if err := launch(); err == nil {
+					pending++
+				}
+			}
+		case <-timer.C:
+			if len(cancels) < r.hedgeParallel {
+				if err := launch(); err == nil {
+					pending++
+				}
+				timer.Reset(r.hedgeDelay)
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+// discardHedged closes the responses of losing attempts.
+func discardHedged(results <-chan hedgeResult, pending int) {
+	for ; pending > 0; pending-- {
+		if result := <-results; result.res != nil {
+			result.res.Body.Close()
+		}
+	}
+}
@@ -2,19 +2,64 @@ package quester
 
 import (
 	"net/http"
+	"time"
 )
 
+// Hooks observe every request sent by a Client. PostResponse receives the
+// response and the error of the request; res is nil when err is a transport
+// error. A non-nil error returned by PostResponse is returned by Do.
 type Hooks interface {
 	PreRequest(req *http.Request) error
-	PostResponse(res *http.Response) error
+	PostResponse(res *http.Response, err error) error
 }
 
+// Interceptor is an optional interface for Hooks. When a hook implements it,
+// Intercept is called after all PreRequest hooks; a non-nil response is used
+// instead of sending the request, e.g. to serve it from a cache.
+type Interceptor interface {
+	Intercept(req *http.Request) (*http.Response, error)
+}
+
+// Replayer is an optional interface for Hooks. After the post hooks ran,
+// Replay reports whether the request should be sent once more, e.g. after
+// refreshing expired credentials. A request is replayed at most once, and
+// only when its body can be rewound.
+type Replayer interface {
+	Replay(res *http.Response, err error) bool
+}
+
+// Observer is an optional interface for Hooks. Observe is called once per Do
+// with the final response or error and the time spent, including retries and
+// other hooks.
+type Observer interface {
+	Observe(req *http.Request, res *http.Response, err error, d time.Duration)
+}
+
+// BodyInspector is an optional interface for Hooks that read the request
+// body. When InspectsBody returns true, a streamed request body is buffered
+// in memory before the PreRequest hooks run, so hooks can read it via
+// req.GetBody. req.Body is replaced by a fresh reader after every hook, so
+// reading it does not consume the body either.
+type BodyInspector interface {
+	InspectsBody() bool
+}
+
+// DefaultHooks is a no-op implementation of Hooks, Interceptor and Replayer.
+// Embed it to implement only the methods you need.
 type DefaultHooks struct{}
 
 func (d *DefaultHooks) PreRequest(req *http.Request) error {
 	return nil
 }
 
-func (d *DefaultHooks) PostResponse(res *http.Response) error {
+func (d *DefaultHooks) PostResponse(res *http.Response, err error) error {
 	return nil
 }
+
+func (d *DefaultHooks) Intercept(req *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+func (d *DefaultHooks) Replay(res *http.Response, err error) bool {
+	return false
+}
@@ -0,0 +1,38 @@
+package quester
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+const idempotencyHeader = "Idempotency-Key"
+
+// SetIdempotencyKey sets the Idempotency-Key header. When retries are enabled
+// and no key is set, each send of a POST or PATCH request gets a new random
+// UUID key, reused across its retry attempts so the server can deduplicate
+// them.
+func (r *Request) SetIdempotencyKey(key string) *Request {
+	return r.SetHeader(idempotencyHeader, key)
+}
+
+// ensureIdempotencyKey generates a key for non-idempotent methods when the
+// client retries requests. It is set on req only, so a builder sent again, or
+// cloned after sending, gets a fresh key.
+func (r *Request) ensureIdempotencyKey(req *http.Request) {
+	if r.client.retry == nil || r.client.retry.MaxAttempts < 2 || req.Header.Get(idempotencyHeader) != "" {
+		return
+	}
+	if req.Method == http.MethodPost || req.Method == http.MethodPatch {
+		req.Header.Set(idempotencyHeader, newUUID())
+	}
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // never returns an error
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
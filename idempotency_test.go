@@ -0,0 +1,97 @@
+package quester
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyServer records the Idempotency-Key of every request, failing the first
+// attempt of each key with 503 when flaky is set.
+func keyServer(t *testing.T, flaky bool) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		keys []string
+		seen = map[string]bool{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := r.Header.Get(idempotencyHeader)
+		keys = append(keys, key)
+		if flaky && !seen[key] {
+			seen[key] = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestIdempotencyKeyPerSend(t *testing.T) {
+	srv, keys := keyServer(t, false)
+	c := NewClient(srv.URL)
+	c.SetRetry(RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	req := c.Post("/", map[string]int{"n": 1})
+	for range 2 {
+		if _, err := req.Do(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := req.Clone().Do(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := keys()
+	if len(got) != 3 {
+		t.Fatalf("got %d requests, want 3", len(got))
+	}
+	seen := map[string]bool{}
+	for _, k := range got {
+		if k == "" || seen[k] {
+			t.Fatalf("keys %q are not unique", got)
+		}
+		seen[k] = true
+	}
+	if k := req.headers.Get(idempotencyHeader); k != "" {
+		t.Fatalf("key %q left on the builder", k)
+	}
+}
+
+func TestIdempotencyKeyStableAcrossRetries(t *testing.T) {
+	srv, keys := keyServer(t, true)
+	c := NewClient(srv.URL)
+	c.SetRetry(RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	if _, err := c.Post("/", map[string]int{"n": 1}).Do(nil); err != nil {
+		t.Fatal(err)
+	}
+	got := keys()
+	if len(got) != 2 || got[0] == "" || got[0] != got[1] {
+		t.Fatalf("keys %q, want the same key on both attempts", got)
+	}
+}
+
+func TestIdempotencyKeyExplicit(t *testing.T) {
+	srv, keys := keyServer(t, false)
+	c := NewClient(srv.URL)
+	c.SetRetry(RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	req := c.Post("/", map[string]int{"n": 1}).SetIdempotencyKey("fixed")
+	for range 2 {
+		if _, err := req.Do(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := keys(); len(got) != 2 || got[0] != "fixed" || got[1] != "fixed" {
+		t.Fatalf("keys %q, want the explicit key", got)
+	}
+}
@@ -0,0 +1,79 @@
+package quester
+
+import (
+	"io"
+	"net/http"
+)
+
+// SetMaxResponseBytes limits the size of response bodies Do will read. Reading
+// past the limit fails with a *BodyTooLargeError. Zero means no limit.
+func (c *Client) SetMaxResponseBytes(n int64) {
+	c.maxResponseBytes = n
+}
+
+// SetMaxRequestBytes rejects request bodies larger than n bytes with a
+// *BodyTooLargeError. Zero means no limit.
+func (c *Client) SetMaxRequestBytes(n int64) {
+	c.maxRequestBytes = n
+}
+
+// limitedBody fails reads once more than limit bytes would be returned.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	err       *BodyTooLargeError
+}
+
+func newLimitedBody(rc io.ReadCloser, limit int64, response bool) *limitedBody {
+	return &limitedBody{
+		ReadCloser: rc,
+		remaining:  limit,
+		err:        &BodyTooLargeError{Limit: limit, Response: response},
+	}
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Probe for a byte past the limit
+		var one [1]byte
+		n, err := b.ReadCloser.Read(one[:])
+		if n > 0 {
+			return 0, b.err
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// limitRequest enforces the request body limit on req.
+func (c *Client) limitRequest(req *http.Request) error {
+	limit := c.maxRequestBytes
+	if limit <= 0 || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.ContentLength > limit {
+		return &BodyTooLargeError{Limit: limit}
+	}
+	if req.ContentLength <= 0 {
+		req.Body = newLimitedBody(req.Body, limit, false)
+	}
+	return nil
+}
+
+// limitResponse enforces the response body limit on res.
+func (c *Client) limitResponse(res *http.Response) error {
+	limit := c.maxResponseBytes
+	if limit <= 0 {
+		return nil
+	}
+	if res.ContentLength > limit {
+		return &BodyTooLargeError{Limit: limit, Response: true}
+	}
+	res.Body = newLimitedBody(res.Body, limit, true)
+	return nil
+}
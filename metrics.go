@@ -0,0 +1,53 @@
+package quester
+
+import (
+	"net/http"
+	"time"
+)
+
+// StatusError is the status reported to a MetricsRecorder for requests that
+// failed without a response.
+const StatusError = 0
+
+// MetricsRecorder receives one observation per request.
+type MetricsRecorder interface {
+	ObserveLatency(method, path string, status int, d time.Duration)
+}
+
+// NopMetricsRecorder discards all observations.
+type NopMetricsRecorder struct{}
+
+func (NopMetricsRecorder) ObserveLatency(method, path string, status int, d time.Duration) {}
+
+// MetricsHook reports the latency and status of every request to Recorder.
+// Requests that fail without a response are reported with StatusError.
+type MetricsHook struct {
+	Recorder MetricsRecorder
+}
+
+// NewMetricsHook creates a metrics hook. A nil recorder discards observations.
+func NewMetricsHook(recorder MetricsRecorder) *MetricsHook {
+	if recorder == nil {
+		recorder = NopMetricsRecorder{}
+	}
+	return &MetricsHook{Recorder: recorder}
+}
+
+func (m *MetricsHook) PreRequest(req *http.Request) error {
+	return nil
+}
+
+func (m *MetricsHook) PostResponse(res *http.Response, err error) error {
+	return nil
+}
+
+func (m *MetricsHook) Observe(req *http.Request, res *http.Response, err error, d time.Duration) {
+	if m.Recorder == nil {
+		return
+	}
+	status := StatusError
+	if err == nil && res != nil {
+		status = res.StatusCode
+	}
+	m.Recorder.ObserveLatency(req.Method, req.URL.Path, status, d)
+}
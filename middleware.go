@@ -5,16 +5,69 @@ import (
 	"net/http"
 )
 
-func LogRequest(req *http.Request) {
-	log.Printf("[Request] %s %s", req.Method, req.URL.String())
-	for k, v := range req.Header {
-		log.Printf("Header: %s = %v", k, v)
+// LogLevel controls how much LoggingHook writes.
+type LogLevel int
+
+const (
+	// LogSummary logs one line per request and response.
+	LogSummary LogLevel = iota
+	// LogHeaders also logs every header.
+	LogHeaders
+)
+
+// LoggingHook logs requests and responses. Sensitive headers are redacted,
+// see Client.SetSensitiveHeaders.
+type LoggingHook struct {
+	Logger *log.Logger // defaults to log.Default()
+	Level  LogLevel
+}
+
+// NewLoggingHook creates a logging hook writing to logger.
+func NewLoggingHook(logger *log.Logger, level LogLevel) *LoggingHook {
+	return &LoggingHook{Logger: logger, Level: level}
+}
+
+func (l *LoggingHook) PreRequest(req *http.Request) error {
+	l.logger().Printf("[Request] %s %s", req.Method, req.URL.String())
+	if l.Level >= LogHeaders {
+		l.logHeaders(RedactHeaders(req.Header, SensitiveHeaders(req.Context())))
 	}
+	return nil
 }
 
-func LogResponse(res *http.Response) {
-	log.Printf("[Response] %d %s", res.StatusCode, res.Status)
-	for k, v := range res.Header {
-		log.Printf("Header: %s = %v", k, v)
+func (l *LoggingHook) PostResponse(res *http.Response, err error) error {
+	if err != nil {
+		l.logger().Printf("[Response] error: %v", err)
+		return nil
+	}
+	l.logger().Printf("[Response] %d %s", res.StatusCode, res.Status)
+	if l.Level >= LogHeaders {
+		sensitive := defaultSensitiveHeaders
+		if res.Request != nil {
+			sensitive = SensitiveHeaders(res.Request.Context())
+		}
+		l.logHeaders(RedactHeaders(res.Header, sensitive))
+	}
+	return nil
+}
+
+func (l *LoggingHook) logger() *log.Logger {
+	if l.Logger != nil {
+		return l.Logger
+	}
+	return log.Default()
+}
+
+func (l *LoggingHook) logHeaders(h http.Header) {
+	for k, v := range h {
+		l.logger().Printf("Header: %s = %v", k, v)
 	}
 }
+
+func LogRequest(req *http.Request) {
+	_ = (&LoggingHook{Level: LogHeaders}).PreRequest(req)
+}
+
+func LogResponse(res *http.Response) {
+	_ = (&LoggingHook{Level: LogHeaders}).PostResponse(res, nil)
+}
@@ -0,0 +1,113 @@
+package quester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// MockResponse is a canned response returned by a Mock.
+type MockResponse struct {
+	Status int // defaults to 200
+	Header http.Header
+	Body   []byte
+	// Encoding compresses Body with "gzip" or "deflate" and sets the
+	// Content-Encoding header, exercising the client's decompression.
+	Encoding string
+}
+
+// Mock is an http.RoundTripper serving registered responses by method and
+// path, for testing code that uses a Client without a network.
+type Mock struct {
+	mu         sync.Mutex
+	responders map[string]func(*http.Request) (*http.Response, error)
+	calls      []*http.Request
+}
+
+// NewMockClient returns a client whose transport is a Mock. Requests go
+// through the regular Do flow, including hooks.
+func NewMockClient() (*Client, *Mock) {
+	m := &Mock{responders: make(map[string]func(*http.Request) (*http.Response, error))}
+	c := NewClient("http://mock.local")
+	c.SetTransport(m)
+	return c, m
+}
+
+// On registers a canned response for method and path.
+func (m *Mock) On(method, path string, res MockResponse) {
+	m.OnFunc(method, path, func(req *http.Request) (*http.Response, error) {
+		return res.response(req)
+	})
+}
+
+// OnFunc registers a function responding to method and path.
+func (m *Mock) OnFunc(method, path string, fn func(*http.Request) (*http.Response, error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responders[method+" "+path] = fn
+}
+
+// Calls returns the requests received so far. Their bodies can be read again.
+func (m *Mock) Calls() []*http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*http.Request(nil), m.calls...)
+}
+
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := req.Clone(req.Context())
+	recorded.Body = io.NopCloser(bytes.NewReader(body))
+
+	m.mu.Lock()
+	m.calls = append(m.calls, recorded)
+	fn, ok := m.responders[req.Method+" "+req.URL.Path]
+	m.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("quester: no mock response for %s %s", req.Method, req.URL.Path)
+	}
+	return fn(recorded)
+}
+
+func (r MockResponse) response(req *http.Request) (*http.Response, error) {
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	body := r.Body
+	if r.Encoding != "" {
+		buf, err := compressBody(r.Encoding, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		body = buf.Bytes()
+		header.Set("Content-Encoding", r.Encoding)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package quester
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// MultipartFile is a file part of a multipart/form-data body.
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	Reader      io.Reader
+	ContentType string // defaults to application/octet-stream
+}
+
+type multipartBody struct {
+	fields   map[string]string
+	files    []MultipartFile
+	boundary string
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// SetMultipartBody sets a multipart/form-data request body. The body is
+// streamed while the request is sent rather than buffered in memory. When
+// every file reader is an io.Seeker, the body can be rewound for retries.
+func (r *Request) SetMultipartBody(fields map[string]string, files ...MultipartFile) *Request {
+	r.body = &multipartBody{
+		fields:   fields,
+		files:    files,
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+	return r
+}
+
+func (m *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// open starts streaming the body through a pipe.
+func (m *multipartBody) open() io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(m.write(pw))
+	}()
+	return pr
+}
+
+func (m *multipartBody) write(dst io.Writer) error {
+	w := multipart.NewWriter(dst)
+	if err := w.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m.fields))
+	for k := range m.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := w.WriteField(k, m.fields[k]); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range m.files {
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(f.FieldName), quoteEscaper.Replace(f.FileName)))
+		h.Set("Content-Type", contentType)
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, f.Reader); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// seekable reports whether all file readers can be rewound.
+func (m *multipartBody) seekable() bool {
+	for _, f := range m.files {
+		if _, ok := f.Reader.(io.Seeker); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// rewind seeks every file reader back to the start and reopens the body.
+func (m *multipartBody) rewind() (io.ReadCloser, error) {
+	for _, f := range m.files {
+		if _, err := f.Reader.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return m.open(), nil
+}
@@ -0,0 +1,138 @@
+package quester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta refreshes tokens this long before they actually expire.
+const tokenExpiryDelta = 30 * time.Second
+
+// OAuth2Hook authenticates requests with an OAuth2 client-credentials token.
+// The token is fetched on first use, cached and refreshed before it expires.
+// A 401 response forces a refresh and replays the request once. It is safe
+// for concurrent use.
+type OAuth2Hook struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient fetches tokens, http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewOAuth2Hook creates a client-credentials hook.
+func NewOAuth2Hook(tokenURL, clientID, clientSecret string, scopes ...string) *OAuth2Hook {
+	return &OAuth2Hook{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+	}
+}
+
+func (o *OAuth2Hook) PreRequest(req *http.Request) error {
+	token, err := o.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (o *OAuth2Hook) PostResponse(res *http.Response, err error) error {
+	if err == nil && res.StatusCode == http.StatusUnauthorized && res.Request != nil {
+		o.invalidate(strings.TrimPrefix(res.Request.Header.Get("Authorization"), "Bearer "))
+	}
+	return nil
+}
+
+// Replay resends requests rejected with 401 once the token was invalidated.
+func (o *OAuth2Hook) Replay(res *http.Response, err error) bool {
+	return err == nil && res.StatusCode == http.StatusUnauthorized
+}
+
+// Token returns a valid access token, fetching a new one when needed.
+func (o *OAuth2Hook) Token(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != "" && (o.expiry.IsZero() || time.Now().Before(o.expiry.Add(-tokenExpiryDelta))) {
+		return o.token, nil
+	}
+
+	token, expiresIn, err := o.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	o.token = token
+	o.expiry = time.Time{}
+	if expiresIn > 0 {
+		o.expiry = time.Now().Add(expiresIn)
+	}
+	return o.token, nil
+}
+
+// invalidate drops the cached token if it is still the one that was rejected,
+// so concurrent 401s trigger a single refresh.
+func (o *OAuth2Hook) invalidate(token string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.token == token {
+		o.token = ""
+	}
+}
+
+func (o *OAuth2Hook) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(o.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+
+	hc := o.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return "", 0, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", 0, fmt.Errorf("quester: token request failed with %s: %s", res.Status, body)
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return "", 0, fmt.Errorf("quester: decode token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", 0, fmt.Errorf("quester: token response has no access_token")
+	}
+	return tok.AccessToken, time.Duration(tok.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,75 @@
+package quester
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FollowPagination sends the request as a GET and follows the rel="next"
+// link of the Link header (RFC 5988) until there is none, maxPages pages were
+// fetched or the context is done. A maxPages of zero or less means no cap.
+//
+// Each page is passed to collect with its raw body kept, so it can be read
+// with Response.Bytes or decoded with Response.Into. An error returned by
+// collect stops the pagination and is returned.
+func (r *Request) FollowPagination(maxPages int, collect func(*Response) error) error {
+	if r.method != "" && r.method != http.MethodGet {
+		return fmt.Errorf("quester: pagination requires GET, got %s", r.method)
+	}
+	page := r.Clone()
+	page.method = http.MethodGet
+	page.keepRawBody = true
+
+	for n := 1; maxPages <= 0 || n <= maxPages; n++ {
+		if err := page.ctxOrDefault().Err(); err != nil {
+			return err
+		}
+		// Links are resolved before rewriting, so the rewriter runs once per page
+		u, err := page.targetURL()
+		if err != nil {
+			return err
+		}
+		resp, err := page.Clone().Do(nil)
+		if err != nil {
+			return err
+		}
+		if err := collect(resp); err != nil {
+			return err
+		}
+
+		next, ok := nextLink(resp.Headers)
+		if !ok {
+			return nil
+		}
+		if page.pageURL, err = u.Parse(next); err != nil {
+			return fmt.Errorf("quester: invalid next link %q: %w", next, err)
+		}
+	}
+	return nil
+}
+
+// nextLink returns the target of the rel="next" link in the Link headers.
+func nextLink(h http.Header) (string, bool) {
+	for _, v := range h.Values("Link") {
+		for _, link := range strings.Split(v, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, p := range strings.Split(params, ";") {
+				key, val, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				// rel may hold several space separated relation types
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1], true
+					}
+				}
+			}
+		}
+	}
+	return "", false
+}
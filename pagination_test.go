@@ -0,0 +1,32 @@
+package quester
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFollowPaginationRewritesOnce(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `<?page=2>; rel="next"`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.SetURLRewriter(func(u *url.URL) *url.URL {
+		u.Path = "/blue" + u.Path
+		return u
+	})
+	if err := c.Get("/items").FollowPagination(0, func(*Response) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/blue/items", "/blue/items?page=2"}
+	if len(paths) != len(want) || paths[0] != want[0] || paths[1] != want[1] {
+		t.Fatalf("requested %q, want %q", paths, want)
+	}
+}
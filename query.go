@@ -0,0 +1,64 @@
+package quester
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Query array styles for SetQueryArray, matching OpenAPI collection formats.
+const (
+	QueryMulti = "multi" // ?ids=1&ids=2
+	QueryCSV   = "csv"   // ?ids=1,2
+	QuerySpace = "space" // ?ids=1%202
+)
+
+// SetQueryArray sets the values of a query parameter rendered with style,
+// one of QueryMulti, QueryCSV or QuerySpace. Unknown styles render as
+// QueryMulti.
+func (r *Request) SetQueryArray(key string, values []string, style string) *Request {
+	r.query[key] = append([]string(nil), values...)
+	if r.queryStyles == nil {
+		r.queryStyles = make(map[string]string)
+	}
+	r.queryStyles[key] = style
+	return r
+}
+
+// encodeQuery encodes q sorted by key, joining the values of keys with a
+// csv or space style into a single parameter.
+func encodeQuery(q url.Values, styles map[string]string) string {
+	if len(styles) == 0 {
+		return q.Encode()
+	}
+
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		vals := make([]string, len(q[k]))
+		for i, v := range q[k] {
+			vals[i] = url.QueryEscape(v)
+		}
+		switch styles[k] {
+		case QueryCSV:
+			vals = []string{strings.Join(vals, ",")}
+		case QuerySpace:
+			vals = []string{strings.Join(vals, "%20")}
+		}
+
+		for _, v := range vals {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			b.WriteByte('=')
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
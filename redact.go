@@ -0,0 +1,55 @@
+package quester
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// redactedValue replaces the values of sensitive headers.
+const redactedValue = "[REDACTED]"
+
+var defaultSensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+type sensitiveKey struct{}
+
+// SetSensitiveHeaders replaces the set of headers masked by the built-in
+// logging and debug helpers. The default set is Authorization, Cookie,
+// Set-Cookie and Proxy-Authorization.
+func (c *Client) SetSensitiveHeaders(keys ...string) {
+	c.sensitive = make([]string, len(keys))
+	for i, k := range keys {
+		c.sensitive[i] = http.CanonicalHeaderKey(k)
+	}
+}
+
+// SensitiveHeaders returns the headers masked by the client.
+func (c *Client) SensitiveHeaders() []string {
+	if c.sensitive == nil {
+		return defaultSensitiveHeaders
+	}
+	return c.sensitive
+}
+
+// SensitiveHeaders returns the sensitive headers of the client sending the
+// request ctx belongs to, or the default set. Hooks can use it with
+// RedactHeaders to mask headers consistently:
+//
+//	safe := quester.RedactHeaders(req.Header, quester.SensitiveHeaders(req.Context()))
+func SensitiveHeaders(ctx context.Context) []string {
+	if keys, ok := ctx.Value(sensitiveKey{}).([]string); ok {
+		return keys
+	}
+	return defaultSensitiveHeaders
+}
+
+// RedactHeaders returns a copy of h with the values of sensitive headers masked.
+func RedactHeaders(h http.Header, sensitive []string) http.Header {
+	out := h.Clone()
+	for k := range out {
+		if slices.Contains(sensitive, http.CanonicalHeaderKey(k)) {
+			out[k] = []string{redactedValue}
+		}
+	}
+	return out
+}
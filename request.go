@@ -3,13 +3,17 @@ package quester
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptrace"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,27 +22,114 @@ type Request struct {
 	method            string
 	path              string
 	headers           http.Header
-	query             map[string]string
+	query             url.Values
 	body              any
 	ctx               context.Context
 	basicAuthUsername string
 	basicAuthPassword string
 	bearerToken       string
 	enableTrace       bool
+	timeout           time.Duration
+	cancel            context.CancelFunc
+	checkStatus       bool
+	expectStatus      []int
+	keepRawBody       bool
+	traceWriter       io.Writer
+	traceHook         func(TraceEvent)
+
+	disableDecompression bool
+	compression          string
+	cookies              []*http.Cookie
+	forceContentType     string
+	pathParams           map[string]string
+	debug                *debugger
+	ctxValues            []contextValue
+	schema               []byte
+	deadline             time.Time
+	errorResult          any
+	hedgeDelay           time.Duration
+	hedgeParallel        int
+	queryStyles          map[string]string
+	attempts             *atomic.Int64
+	pageURL              *url.URL
+	rawPath              bool
+	onRetry              func(attempt int, res *http.Response, err error, delay time.Duration)
+	sentBody             []byte
+	tee                  io.Writer
+	hooks                []Hooks
+	disallowUnknown      bool
+	onUnknownField       func(path string)
+	responseTee          io.Writer
+	skipErrorHandler     bool
+	deadlineHeader       string
+}
+
+// requestHooksKey carries the hooks added by Request.Use in its context.
+type requestHooksKey struct{}
+
+// attemptsKey carries the attempt counter of a request in its context.
+type attemptsKey struct{}
+
+type contextValue struct {
+	key, val any
 }
 
-// EnableTrace enables HTTP trace/debug.
+type xmlBody struct {
+	v      any
+	header bool
+}
+
+// EnableTrace enables HTTP trace/debug. Events are written to stderr unless
+// a sink is set via SetTraceWriter or SetTraceHook.
 func (r *Request) EnableTrace() *Request {
 	r.enableTrace = true
 	return r
 }
 
-// SetMethod sets the HTTP method.
+// KeepRawBody keeps the raw response bytes on the Response even when the
+// body is decoded, see Response.Bytes.
+func (r *Request) KeepRawBody() *Request {
+	r.keepRawBody = true
+	return r
+}
+
+// ForceJSON decodes the response as JSON regardless of its Content-Type.
+func (r *Request) ForceJSON() *Request {
+	r.forceContentType = "application/json"
+	return r
+}
+
+// ForceXML decodes the response as XML regardless of its Content-Type.
+func (r *Request) ForceXML() *Request {
+	r.forceContentType = "application/xml"
+	return r
+}
+
+// Use adds a hook for this request only. It runs after the client hooks, in
+// the same PreRequest and PostResponse flow.
+func (r *Request) Use(h Hooks) *Request {
+	r.hooks = append(r.hooks, h)
+	return r
+}
+
+// SetMethod sets the HTTP method. It is uppercased, as methods are case
+// sensitive and the standard ones are all uppercase; use SetMethodRaw to send
+// a method exactly as written.
 func (r *Request) SetMethod(method string) *Request {
 	r.method = strings.ToUpper(method)
 	return r
 }
 
+// SetMethodRaw sets the HTTP method without changing its case, e.g. for
+// extension methods of WebDAV or other protocols. Do fails when the method is
+// not a valid token.
+func (r *Request) SetMethodRaw(method string) *Request {
+	r.method = method
+	return r
+}
+
+// SetBasicAuth sets HTTP basic auth credentials. See ClearAuth for the
+// precedence between authentication methods.
 func (r *Request) SetBasicAuth(username, password string) *Request {
 	r.basicAuthUsername = username
 	r.basicAuthPassword = password
@@ -51,9 +142,73 @@ func (r *Request) SetBearerToken(token string) *Request {
 	return r
 }
 
+// ClearAuth removes basic auth credentials, the bearer token and any
+// Authorization header from the request.
+//
+// Authentication precedence: an Authorization header set via SetHeader wins
+// over basic auth and bearer tokens. Setting both basic auth and a bearer
+// token is ambiguous and makes Do fail with ErrConflictingAuth.
+func (r *Request) ClearAuth() *Request {
+	r.basicAuthUsername = ""
+	r.basicAuthPassword = ""
+	r.bearerToken = ""
+	r.headers.Del("Authorization")
+	return r
+}
+
+// applyAuth sets the Authorization header following the documented precedence.
+func (r *Request) applyAuth(req *http.Request) error {
+	hasBasic := r.basicAuthUsername != "" || r.basicAuthPassword != ""
+	if r.headers.Get("Authorization") != "" {
+		return nil
+	}
+	if hasBasic && r.bearerToken != "" {
+		return ErrConflictingAuth
+	}
+
+	// Set Basic Auth if present
+	if hasBasic {
+		req.SetBasicAuth(r.basicAuthUsername, r.basicAuthPassword)
+	}
+
+	// Set Bearer Token if present
+	if r.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.bearerToken)
+	}
+	return nil
+}
+
 // SetPath sets the request path (relative to base URL).
 func (r *Request) SetPath(path string) *Request {
 	r.path = path
+	r.rawPath = false
+	return r
+}
+
+// SetRawPath sets an already percent-encoded path, relative to the base URL,
+// that is sent exactly as given, e.g. to keep an encoded slash in a segment.
+// Path parameters are not substituted.
+func (r *Request) SetRawPath(path string) *Request {
+	r.path = path
+	r.rawPath = true
+	return r
+}
+
+// SetPathParam sets the value of a {key} placeholder in the path. The value is
+// escaped when the path is resolved.
+func (r *Request) SetPathParam(key, value string) *Request {
+	if r.pathParams == nil {
+		r.pathParams = make(map[string]string)
+	}
+	r.pathParams[key] = value
+	return r
+}
+
+// SetPathParams sets several path placeholders.
+func (r *Request) SetPathParams(params map[string]string) *Request {
+	for k, v := range params {
+		r.SetPathParam(k, v)
+	}
 	return r
 }
 
@@ -63,121 +218,366 @@ func (r *Request) SetHeader(key, value string) *Request {
 	return r
 }
 
-// SetQuery adds a query parameter.
+// SetCookie adds a cookie to this request only.
+func (r *Request) SetCookie(c *http.Cookie) *Request {
+	r.cookies = append(r.cookies, c)
+	return r
+}
+
+// SetQuery sets a query parameter, replacing any existing values for key.
 func (r *Request) SetQuery(key, value string) *Request {
-	r.query[key] = value
+	r.query.Set(key, value)
 	return r
 }
 
 // SetQueries adds a query parameter.
 func (r *Request) SetQueries(queries map[string]string) *Request {
 	for k, q := range queries {
-		r.query[k] = q
+		r.query.Set(k, q)
 	}
 	return r
 }
 
-// SetBody sets the request body.
+// AddQuery appends a query parameter value, allowing repeated keys.
+func (r *Request) AddQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// SetBody sets the request body. Strings and byte slices are sent verbatim
+// as text/plain and application/octet-stream, io.Readers are streamed, and
+// other values are encoded by the encoder registered for the Content-Type,
+// JSON by default. A Content-Type set via SetHeader takes precedence.
 func (r *Request) SetBody(body any) *Request {
 	r.body = body
 	return r
 }
 
+// SetFormBody sets an application/x-www-form-urlencoded request body.
+// It replaces any body set earlier; the last call to SetBody or SetFormBody wins.
+func (r *Request) SetFormBody(values url.Values) *Request {
+	r.body = values
+	return r
+}
+
+// SkipErrorHandler disables the client's error handler for this request, for
+// endpoints with non-standard status semantics.
+func (r *Request) SkipErrorHandler() *Request {
+	r.skipErrorHandler = true
+	return r
+}
+
+// TeeResponseBody copies the response body to w while Do reads it, so it can
+// be logged and decoded in one pass without buffering it. Error bodies and
+// bytes left over by the decoder are copied too.
+func (r *Request) TeeResponseBody(w io.Writer) *Request {
+	r.responseTee = w
+	return r
+}
+
+// ExpectContinue sends the Expect: 100-continue header, so the body is only
+// sent once the server accepted the request headers, or after the client's
+// expect-continue timeout (see Client.SetExpectContinueTimeout). A rejected
+// request does not consume the body; it is still only retried or replayed
+// when the body can be rewound, which is not the case for streamed
+// io.Reader bodies.
+func (r *Request) ExpectContinue() *Request {
+	return r.SetHeader("Expect", "100-continue")
+}
+
+// SentBody returns the encoded request body after the request was sent,
+// before compression. It is nil for streamed io.Reader, multipart and sized
+// bodies; use TeeBody to capture those. Hooks can read buffered bodies again
+// via req.GetBody.
+func (r *Request) SentBody() []byte {
+	return r.sentBody
+}
+
+// TeeBody copies the request body to w as it is encoded or streamed. Bodies
+// are copied once, even when the request is retried. A streamed io.Reader
+// body is then sent without a known length; multipart and sized bodies keep
+// their framing.
+func (r *Request) TeeBody(w io.Writer) *Request {
+	r.tee = w
+	return r
+}
+
+// SetXMLBody sets a body encoded with encoding/xml, sent as application/xml
+// unless a Content-Type is set. withHeader prepends the standard XML
+// declaration, as expected by many SOAP services.
+func (r *Request) SetXMLBody(v any, withHeader bool) *Request {
+	r.body = &xmlBody{v: v, header: withHeader}
+	return r
+}
+
 // SetContext sets a custom context.
 func (r *Request) SetContext(ctx context.Context) *Request {
 	r.ctx = ctx
 	return r
 }
 
-// SetTimeout sets a timeout for the request context.
+// WithContext returns a copy of the request, as made by Clone, using ctx. The
+// original request is left untouched, so a request template can be shared
+// between goroutines that each send it with their own context.
+func (r *Request) WithContext(ctx context.Context) *Request {
+	c := r.Clone()
+	c.ctx = ctx
+	return c
+}
+
+// SetContextValue attaches a value to the request context, so hooks can read
+// it via req.Context().Value(key). Values are applied when the request is sent,
+// on top of any context set via SetContext. Use an unexported key type to avoid
+// collisions between packages:
+//
+//	type ctxKey struct{}
+//
+//	req.SetContextValue(ctxKey{}, correlationID)
+func (r *Request) SetContextValue(key, val any) *Request {
+	r.ctxValues = append(r.ctxValues, contextValue{key: key, val: val})
+	return r
+}
+
+// SetTimeout sets a timeout for the request context. The timeout is applied
+// when the request is sent, on top of any context set via SetContext, so
+// cancellation of the parent context still propagates regardless of call order.
 func (r *Request) SetTimeout(d time.Duration) *Request {
-	if r.ctx != nil {
-		var cancel context.CancelFunc
-		r.ctx, cancel = context.WithTimeout(r.ctx, d)
-		go func() {
-			<-r.ctx.Done()
-			cancel()
-		}()
-	} else {
-		var cancel context.CancelFunc
-		r.ctx, cancel = context.WithTimeout(context.Background(), d)
-		go func() {
-			<-r.ctx.Done()
-			cancel()
-		}()
-	}
+	r.timeout = d
+	return r
+}
+
+// SetDeadline sets an absolute deadline for the request. When combined with
+// SetTimeout or a parent context deadline, the earliest one wins.
+func (r *Request) SetDeadline(t time.Time) *Request {
+	r.deadline = t
 	return r
 }
 
-// Do sends the request and decodes the response into result.
+// Clone returns a copy of the request that can be modified independently.
+// Headers, query and path parameters, query styles, cookies, context values,
+// hooks and expected statuses are copied; the body and context are shared
+// until replaced.
+func (r *Request) Clone() *Request {
+	c := *r
+	c.headers = r.headers.Clone()
+	c.query = url.Values{}
+	for k, v := range r.query {
+		c.query[k] = slices.Clone(v)
+	}
+	c.cookies = slices.Clone(r.cookies)
+	c.ctxValues = slices.Clone(r.ctxValues)
+	c.pathParams = maps.Clone(r.pathParams)
+	c.queryStyles = maps.Clone(r.queryStyles)
+	c.expectStatus = slices.Clone(r.expectStatus)
+	c.hooks = slices.Clone(r.hooks)
+	c.cancel = nil
+	return &c
+}
+
+// Do sends the request and decodes the response into result. When result is
+// an io.Writer, the body is copied into it without decoding. An empty body
+// leaves result untouched. The client's error handler, if any, then checks
+// the response.
 func (r *Request) Do(result any) (*Response, error) {
-	fullURL := r.client.BaseURL + r.path
+	resp, err := r.execute(result)
+	if err == nil && r.client.errorHandler != nil && !r.skipErrorHandler {
+		err = r.client.errorHandler(resp)
+	}
+	return resp, err
+}
+
+// maxTrailerDrain bounds the unread body Do discards to reach trailers.
+const maxTrailerDrain = 1 << 20
+
+// execute sends the request and reads the response for Do.
+func (r *Request) execute(result any) (*Response, error) {
+	defer r.cleanup()
+
+	start := time.Now()
+	res, err := r.send()
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Copy everything read from the body, including drained bytes
+	if r.responseTee != nil {
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(res.Body, r.responseTee), res.Body}
+	}
+
+	// Read body
+	resp := newResponse(r.client, res)
+	resp.ReceivedAt = time.Now()
+	resp.Attempts = int(r.attempts.Load())
+	defer func() {
+		resp.Duration = time.Since(start)
+	}()
 
-	// Build query
-	if len(r.query) > 0 {
-		q := url.Values{}
-		for k, v := range r.query {
-			q.Set(k, v)
+	contentType := res.Header.Get("Content-Type")
+	if r.forceContentType != "" {
+		contentType = r.forceContentType
+	} else if ambiguousType(contentType) {
+		// Fall back to what the request asked for
+		if accepted := r.acceptedType(); accepted != "" {
+			contentType = accepted
 		}
-		fullURL += "?" + q.Encode()
 	}
 
-	var bodyReader io.Reader
-	switch b := r.body.(type) {
-	case nil:
-	case io.Reader:
-		bodyReader = b
-	default:
-		buf := &bytes.Buffer{}
-		if err := json.NewEncoder(buf).Encode(b); err != nil {
-			return nil, err
+	if (r.checkStatus || r.errorResult != nil) && !r.statusAccepted(res.StatusCode) {
+		return resp, r.statusError(resp, res, contentType)
+	}
+
+	// HEAD, 204 and 304 responses carry no body to decode
+	if bodyless(res) {
+		return resp, nil
+	}
+	// Stream downloads straight into writers, whatever the content type
+	if w, ok := result.(io.Writer); ok {
+		resp.BytesWritten, err = io.Copy(w, res.Body)
+		return resp, err
+	}
+
+	validate := r.schema != nil && isJSON(contentType)
+
+	var body io.Reader = res.Body
+	if r.keepRawBody || validate {
+		if resp.raw, err = io.ReadAll(res.Body); err != nil {
+			return resp, err
 		}
-		bodyReader = buf
-		if r.headers.Get("Content-Type") == "" {
-			r.headers.Set("Content-Type", "application/json")
+		body = bytes.NewReader(resp.raw)
+	}
+
+	if validate {
+		if err := validateSchema(r.schema, resp.raw); err != nil {
+			return resp, err
 		}
 	}
 
-	var trace *httptrace.ClientTrace
-	if r.enableTrace {
-		trace = &httptrace.ClientTrace{
-			DNSStart: func(info httptrace.DNSStartInfo) {
-				println("[TRACE] DNS Start:", info.Host)
-			},
-			DNSDone: func(info httptrace.DNSDoneInfo) {
-				println("[TRACE] DNS Done:", info.Addrs)
-			},
-			ConnectStart: func(network, addr string) {
-				println("[TRACE] Connect Start:", network, addr)
-			},
-			ConnectDone: func(network, addr string, err error) {
-				println("[TRACE] Connect Done:", network, addr, err)
-			},
-			GotFirstResponseByte: func() {
-				println("[TRACE] Got First Byte:", time.Now().Format(time.RFC3339Nano))
-			},
+	// Decode response if provided
+	if result != nil {
+		capture := &captureReader{r: body}
+		body = capture
+		var ok bool
+		if r.checksUnknownFields(contentType) {
+			ok, err = true, r.decodeJSON(contentType, body, result)
+		} else {
+			ok, err = r.client.decode(contentType, body, result)
+		}
+		// Bodies of unknown length may still turn out empty
+		if err == io.EOF && len(capture.head) == 0 {
+			err = nil
+		}
+		if ok && err != nil && capture.readErr == nil {
+			err = &DecodeError{ContentType: contentType, Snippet: string(capture.head), Err: err}
+		}
+		if !ok {
+			if resp.raw == nil {
+				resp.raw, _ = io.ReadAll(body)
+			}
+			resp.Body = resp.raw
+		}
+	}
+
+	// Announced trailers arrive after the last body byte, so read past what
+	// the decoder consumed. The drain is bounded so that endless streams
+	// cannot block Do.
+	if len(res.Trailer) > 0 {
+		_, _ = io.CopyN(io.Discard, res.Body, maxTrailerDrain)
+	}
+
+	return resp, err
+}
+
+// MustDo is like Do but panics on error, with the method, URL and status in
+// the message. It is meant for scripts and tests, not production code.
+func (r *Request) MustDo(result any) *Response {
+	resp, err := r.Do(result)
+	if err != nil {
+		target := r.path
+		if u, uerr := r.buildURL(); uerr == nil {
+			target = u.String()
 		}
+		if resp != nil {
+			panic(fmt.Sprintf("quester: %s %s: %s: %v", r.method, target, resp.StatusText, err))
+		}
+		panic(fmt.Sprintf("quester: %s %s: %v", r.method, target, err))
+	}
+	return resp
+}
+
+// send builds the request and sends it through the client. The caller must
+// close the response body and call cleanup.
+func (r *Request) send() (*http.Response, error) {
+	if r.client.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := r.buildURL()
+	if err != nil {
+		return nil, err
+	}
+
+	bodyReader, err := r.encodeBody()
+	if err != nil {
+		return nil, err
 	}
 
 	ctx := r.ctxOrDefault()
-	if trace != nil {
-		ctx = httptrace.WithClientTrace(ctx, trace)
+	for _, v := range r.ctxValues {
+		ctx = context.WithValue(ctx, v.key, v.val)
+	}
+	// The context is always cancellable, so streamed responses can be aborted
+	if deadline, ok := r.effectiveDeadline(); ok {
+		ctx, r.cancel = context.WithDeadline(ctx, deadline)
+	} else {
+		ctx, r.cancel = context.WithCancel(ctx)
+	}
+	if r.debug != nil {
+		ctx = context.WithValue(ctx, debugKey{}, r.debug)
+	}
+	if r.onRetry != nil {
+		ctx = context.WithValue(ctx, onRetryKey{}, r.onRetry)
+	}
+	if len(r.hooks) > 0 {
+		ctx = context.WithValue(ctx, requestHooksKey{}, r.hooks)
+	}
+	r.attempts = new(atomic.Int64)
+	ctx = context.WithValue(ctx, attemptsKey{}, r.attempts)
+	if r.enableTrace {
+		ctx = httptrace.WithClientTrace(ctx, r.clientTrace())
 	}
 
 	// Build request
-	req, err := http.NewRequestWithContext(ctx, r.method, fullURL, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, r.method, u.String(), bodyReader)
 	if err != nil {
+		if _, ok := r.body.(*multipartBody); ok {
+			bodyReader.(io.Closer).Close()
+		}
 		return nil, err
 	}
 
-	// Set Basic Auth if present
-	if r.basicAuthUsername != "" || r.basicAuthPassword != "" {
-		req.SetBasicAuth(r.basicAuthUsername, r.basicAuthPassword)
+	switch b := r.body.(type) {
+	case *multipartBody:
+		if b.seekable() {
+			req.GetBody = b.rewind
+		}
+	case *sizedBody:
+		b.apply(req)
 	}
 
-	// Set Bearer Token if present
-	if r.bearerToken != "" && req.Header.Get("Authorization") == "" {
-		req.Header.Set("Authorization", "Bearer "+r.bearerToken)
+	if err := r.applyAuth(req); err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	for _, c := range r.cookies {
+		req.AddCookie(c)
 	}
 
 	// Add per-request headers
@@ -186,36 +586,296 @@ func (r *Request) Do(result any) (*Response, error) {
 			req.Header.Add(k, v)
 		}
 	}
+	r.ensureIdempotencyKey(req)
+	// The transport only decompresses gzip it asked for itself, so asking
+	// explicitly keeps encoded bodies as sent
+	if r.disableDecompression && req.Header.Get("Accept-Encoding") == "" && r.client.Headers.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+	if deadline, ok := ctx.Deadline(); ok && r.deadlineHeader != "" {
+		req.Header.Set(r.deadlineHeader, strconv.FormatInt(deadline.UnixMilli(), 10))
+	}
+
+	if err := r.client.limitRequest(req); err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
 
 	// Send
-	res, err := r.client.Do(req)
+	res, err := r.do(req)
 	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
+	if !r.disableDecompression {
+		if err := decompress(res); err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+	}
+	if err := r.client.limitResponse(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res, nil
+}
 
-	// Read body
-	resp := &Response{
-		Status:     res.StatusCode,
-		Headers:    res.Header,
-		Body:       res,
-		StatusText: res.Status,
+// buildURL returns the request URL, passed through the client URL rewriter.
+func (r *Request) buildURL() (*url.URL, error) {
+	u, err := r.targetURL()
+	if err != nil {
+		return nil, err
+	}
+	// Pagination links are rewritten too, so they reach the same host
+	if r.client.rewriteURL != nil {
+		if rewritten := r.client.rewriteURL(u); rewritten != nil {
+			u = rewritten
+		}
 	}
+	return u, nil
+}
 
-	// Decode response if provided
-	if result != nil {
-		contentType := res.Header.Get("Content-Type")
-		switch {
-		case strings.Contains(contentType, "application/json"):
-			err = json.NewDecoder(res.Body).Decode(result)
-		case strings.Contains(contentType, "application/xml"), strings.Contains(contentType, "text/xml"):
-			err = xml.NewDecoder(res.Body).Decode(result)
-		default:
-			resp.Body, _ = io.ReadAll(res.Body)
+// targetURL joins the base URL with the resolved path and appends the query,
+// unless a pagination link is being followed.
+func (r *Request) targetURL() (*url.URL, error) {
+	// Pagination links are followed as sent by the server
+	if r.pageURL != nil {
+		u := *r.pageURL
+		return &u, nil
+	}
+	var u *url.URL
+	if r.rawPath {
+		var err error
+		if u, err = joinRawURL(r.client.BaseURL, r.path); err != nil {
+			return nil, err
+		}
+	} else {
+		path, err := r.resolvePath()
+		if err != nil {
+			return nil, err
+		}
+		if u, err = joinURL(r.client.BaseURL, path); err != nil {
+			return nil, err
 		}
 	}
 
-	return resp, err
+	// Build query, encoded in key order
+	if q := r.buildQuery(); len(q) > 0 {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += encodeQuery(q, r.queryStyles)
+	}
+	return u, nil
+}
+
+// joinURL appends path to the base URL with exactly one slash between them,
+// so a base of "https://api.com/v1/" and a path of "/users" give
+// "https://api.com/v1/users". An absolute path URL is used as is.
+func joinURL(base, path string) (*url.URL, error) {
+	if path == "" {
+		return url.Parse(base)
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return u, nil
+	}
+	if base == "" {
+		return url.Parse(path)
+	}
+	return url.Parse(strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/"))
+}
+
+// joinRawURL appends the encoded rawPath to base, keeping its escaping.
+func joinRawURL(base, rawPath string) (*url.URL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	escaped := strings.TrimRight(u.EscapedPath(), "/") + "/" + strings.TrimLeft(rawPath, "/")
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return nil, fmt.Errorf("quester: invalid raw path %q: %w", rawPath, err)
+	}
+	u.Path, u.RawPath = path, escaped
+	return u, nil
+}
+
+// resolvePath replaces {name} placeholders in the path with escaped path
+// parameters. Unresolved placeholders are an error.
+func (r *Request) resolvePath() (string, error) {
+	if !strings.Contains(r.path, "{") {
+		return r.path, nil
+	}
+	var b strings.Builder
+	rest := r.path
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("quester: unterminated path parameter in %q", r.path)
+		}
+		name := rest[start+1 : start+end]
+		value, ok := r.pathParams[name]
+		if !ok {
+			return "", fmt.Errorf("quester: unresolved path parameter %q in %q", name, r.path)
+		}
+		b.WriteString(rest[:start])
+		b.WriteString(url.PathEscape(value))
+		rest = rest[start+end+1:]
+	}
+	b.WriteString(rest)
+	return b.String(), nil
+}
+
+// buildQuery merges the client default query with the request query; request
+// values replace defaults with the same key.
+func (r *Request) buildQuery() url.Values {
+	if len(r.client.query) == 0 {
+		return r.query
+	}
+	q := url.Values{}
+	for k, v := range r.client.query {
+		q[k] = v
+	}
+	for k, v := range r.query {
+		q[k] = v
+	}
+	return q
+}
+
+// closeRequestBody closes the body of a request that will not be sent, like
+// the transport does, so streaming bodies stop producing.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// teeStream copies a streamed body to the TeeBody writer, if any, keeping the
+// Close method of rd.
+func (r *Request) teeStream(rd io.Reader) io.Reader {
+	if r.tee == nil {
+		return rd
+	}
+	tee := io.TeeReader(rd, r.tee)
+	if c, ok := rd.(io.Closer); ok {
+		return struct {
+			io.Reader
+			io.Closer
+		}{tee, c}
+	}
+	return tee
+}
+
+// encodeBody encodes the request body and sets its Content-Type.
+func (r *Request) encodeBody() (io.Reader, error) {
+	var data []byte
+	body := r.body
+	if body == nil && r.client.defaultBody != nil {
+		switch r.method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			body = r.client.defaultBody()
+		}
+	}
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case io.Reader:
+		// Streamed bodies are sent as is, without compression
+		if r.tee != nil {
+			return io.TeeReader(b, r.tee), nil
+		}
+		return b, nil
+	case *multipartBody:
+		r.headers.Set("Content-Type", b.contentType())
+		// Only the first attempt is copied; rewinds for retries are not
+		return r.teeStream(b.open()), nil
+	case *sizedBody:
+		rd, err := b.open()
+		if err != nil {
+			return nil, err
+		}
+		return r.teeStream(rd), nil
+	case string:
+		// Raw bodies are sent verbatim, not JSON encoded
+		data = []byte(b)
+		if r.headers.Get("Content-Type") == "" {
+			r.headers.Set("Content-Type", "text/plain; charset=utf-8")
+		}
+	case []byte:
+		data = b
+		if r.headers.Get("Content-Type") == "" {
+			r.headers.Set("Content-Type", "application/octet-stream")
+		}
+	case *xmlBody:
+		if r.headers.Get("Content-Type") == "" {
+			r.headers.Set("Content-Type", "application/xml")
+		}
+		buf := &bytes.Buffer{}
+		if b.header {
+			buf.WriteString(xml.Header)
+		}
+		if err := xmlEncoder.Encode(buf, b.v); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	case url.Values:
+		data = []byte(b.Encode())
+		if r.headers.Get("Content-Type") == "" {
+			r.headers.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	default:
+		if r.headers.Get("Content-Type") == "" {
+			r.headers.Set("Content-Type", "application/json")
+		}
+		// Unknown content types keep the historical JSON encoding
+		enc, ok := r.client.encoder(r.headers.Get("Content-Type"))
+		if !ok {
+			enc, _ = r.client.encoder("application/json")
+		}
+		buf := &bytes.Buffer{}
+		if err := enc.Encode(buf, b); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+	r.sentBody = data
+	if r.tee != nil {
+		if _, err := r.tee.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.compression != "" {
+		buf, err := compressBody(r.compression, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		r.headers.Set("Content-Encoding", r.compression)
+		return buf, nil
+	}
+	// Buffered bodies can be replayed on retry via req.GetBody
+	return bytes.NewReader(data), nil
+}
+
+// effectiveDeadline returns the earliest of the request timeout, the client
+// timeout and the deadline.
+func (r *Request) effectiveDeadline() (time.Time, bool) {
+	deadline := r.deadline
+	now := time.Now()
+	// The client timeout is read at request time and enforced only here, so
+	// assigning the Timeout field takes effect for new requests
+	for _, d := range []time.Duration{r.timeout, r.client.Timeout} {
+		if d <= 0 {
+			continue
+		}
+		if t := now.Add(d); deadline.IsZero() || t.Before(deadline) {
+			deadline = t
+		}
+	}
+	return deadline, !deadline.IsZero()
 }
 
 func (r *Request) ctxOrDefault() context.Context {
@@ -224,3 +884,11 @@ func (r *Request) ctxOrDefault() context.Context {
 	}
 	return context.Background()
 }
+
+// cleanup releases resources held by the request context once Do returns.
+func (r *Request) cleanup() {
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
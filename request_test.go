@@ -0,0 +1,215 @@
+package quester
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	Name string `json:"name"`
+}
+
+func TestDoEmptyBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"zero length", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", "0")
+		}},
+		{"no content", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNoContent)
+		}},
+		{"chunked", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.(http.Flusher).Flush()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			result := item{Name: "unchanged"}
+			if _, err := NewClient(srv.URL).Get("/").Do(&result); err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if result.Name != "unchanged" {
+				t.Fatalf("result modified: %+v", result)
+			}
+		})
+	}
+}
+
+func TestDoBlankBodyIsDecodeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("  \n"))
+	}))
+	defer srv.Close()
+
+	var result item
+	_, err := NewClient(srv.URL).Get("/").Do(&result)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("got %v, want *DecodeError", err)
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name, base, path, want string
+	}{
+		{"base without slash, path with slash", "https://api.com", "/users", "https://api.com/users"},
+		{"base with slash, path with slash", "https://api.com/", "/users", "https://api.com/users"},
+		{"base without slash, path without slash", "https://api.com", "users", "https://api.com/users"},
+		{"base with slash, path without slash", "https://api.com/", "users", "https://api.com/users"},
+		{"empty path", "https://api.com/v1", "", "https://api.com/v1"},
+		{"absolute path", "https://api.com/v1", "https://other.com/users", "https://other.com/users"},
+		{"prefix", "https://api.com/v1", "/users/1", "https://api.com/v1/users/1"},
+		{"prefix with slash", "https://api.com/v1/", "/users/1", "https://api.com/v1/users/1"},
+		{"prefix, path without slash", "https://api.com/v1", "users?page=2", "https://api.com/v1/users?page=2"},
+		{"empty base", "", "/users", "/users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := joinURL(tt.base, tt.path)
+			if err != nil {
+				t.Fatalf("joinURL(%q, %q): %v", tt.base, tt.path, err)
+			}
+			if got := u.String(); got != tt.want {
+				t.Fatalf("joinURL(%q, %q) = %q, want %q", tt.base, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// authServer records the Authorization header of the last request.
+func authServer(t *testing.T, got *string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAuthPrecedence(t *testing.T) {
+	var got string
+	c := NewClient(authServer(t, &got).URL)
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+
+	tests := []struct {
+		name string
+		req  *Request
+		want string
+	}{
+		{"basic", c.Get("/").SetBasicAuth("user", "pass"), basic},
+		{"bearer", c.Get("/").SetBearerToken("token"), "Bearer token"},
+		{"header beats basic", c.Get("/").SetBasicAuth("user", "pass").SetHeader("Authorization", "Custom x"), "Custom x"},
+		{"header beats bearer", c.Get("/").SetBearerToken("token").SetHeader("Authorization", "Custom x"), "Custom x"},
+		{"header beats both", c.Get("/").SetBasicAuth("user", "pass").SetBearerToken("token").SetHeader("Authorization", "Custom x"), "Custom x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			if _, err := tt.req.Do(nil); err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthConflict(t *testing.T) {
+	var got string
+	c := NewClient(authServer(t, &got).URL)
+
+	_, err := c.Get("/").SetBasicAuth("user", "pass").SetBearerToken("token").Do(nil)
+	if !errors.Is(err, ErrConflictingAuth) {
+		t.Fatalf("got %v, want ErrConflictingAuth", err)
+	}
+	if got != "" {
+		t.Fatalf("request sent with Authorization %q", got)
+	}
+}
+
+func TestClearAuth(t *testing.T) {
+	var got string
+	c := NewClient(authServer(t, &got).URL)
+
+	got = "unset"
+	req := c.Get("/").
+		SetBasicAuth("user", "pass").
+		SetBearerToken("token").
+		SetHeader("Authorization", "Custom x").
+		ClearAuth()
+	if _, err := req.Do(nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("Authorization = %q after ClearAuth, want none", got)
+	}
+
+	// Auth set after ClearAuth applies normally
+	if _, err := req.SetBearerToken("fresh").Do(nil); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if got != "Bearer fresh" {
+		t.Fatalf("Authorization = %q, want %q", got, "Bearer fresh")
+	}
+}
+
+func TestTeeBodyStreamed(t *testing.T) {
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		// Fail the first attempt so the body is rewound once
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL)
+	c.SetRetry(RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	tests := []struct {
+		name string
+		req  func() *Request
+	}{
+		{"multipart", func() *Request {
+			file := MultipartFile{FieldName: "file", FileName: "a.txt", Reader: strings.NewReader("hello")}
+			return c.Put("/", nil).SetMultipartBody(map[string]string{"k": "v"}, file)
+		}},
+		{"sized", func() *Request {
+			return c.Put("/", nil).SetBodyWithLength(strings.NewReader("hello"), 5)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			var tee bytes.Buffer
+			if _, err := tt.req().TeeBody(&tee).Do(nil); err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if len(received) != 2 {
+				t.Fatalf("got %d attempts, want 2", len(received))
+			}
+			if got := tee.String(); got == "" || got != received[1] {
+				t.Fatalf("teed %q, sent %q", got, received[1])
+			}
+		})
+	}
+}
@@ -1,7 +1,14 @@
 package quester
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Response struct {
@@ -9,4 +16,147 @@ type Response struct {
 	StatusText string
 	Headers    http.Header
 	Body       any
+	// Duration is the time from sending the request until the body was read,
+	// or until the headers arrived for streamed responses.
+	Duration time.Duration
+	// ReceivedAt is when the response headers arrived.
+	ReceivedAt time.Time
+	// Attempts is the number of times the request was sent, including retries.
+	Attempts int
+	// BytesWritten is the number of body bytes copied when Do was given an
+	// io.Writer as result.
+	BytesWritten int64
+	// FromCache reports whether the response was served by a CacheHook.
+	FromCache bool
+
+	raw    []byte
+	stream *streamBody
+	client *Client
+	res    *http.Response
+}
+
+// Bytes returns the raw response body. It is only available when the request
+// used KeepRawBody, or when the body could not be decoded.
+func (r *Response) Bytes() []byte {
+	return r.raw
+}
+
+// String returns the raw response body as a string.
+func (r *Response) String() string {
+	return string(r.raw)
+}
+
+// Raw returns the buffered response body, or an error when it was not
+// buffered; see KeepRawBody.
+func (r *Response) Raw() ([]byte, error) {
+	if r.raw == nil {
+		return nil, errors.New("quester: response body is not buffered, use KeepRawBody")
+	}
+	return r.raw, nil
+}
+
+// JSON decodes the buffered response body into a map, whatever its
+// Content-Type, e.g. for quick inspection without declaring a struct.
+func (r *Response) JSON() (map[string]any, error) {
+	raw, err := r.Raw()
+	if err != nil {
+		return nil, err
+	}
+	body, err := transcode(r.ContentType(), bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.NewDecoder(body).Decode(&m); err != nil {
+		return nil, &DecodeError{ContentType: r.ContentType(), Snippet: string(raw[:min(len(raw), decodeSnippetLen)]), Err: err}
+	}
+	return m, nil
+}
+
+// Into decodes the raw response body into v based on the response Content-Type.
+func (r *Response) Into(v any) error {
+	contentType := r.Headers.Get("Content-Type")
+	ok, err := r.client.decode(contentType, bytes.NewReader(r.raw), v)
+	if !ok {
+		return fmt.Errorf("quester: no decoder for content type %q", contentType)
+	}
+	return err
+}
+
+// Trailers returns the trailers sent after the response body. They are only
+// populated once the body was read to the end: Do drains up to 1 MiB past the
+// decoded value when the response announces trailers in its Trailer header,
+// while bodies returned by Stream must be drained by the caller first.
+func (r *Response) Trailers() http.Header {
+	if r.res == nil {
+		return nil
+	}
+	return r.res.Trailer
+}
+
+// Header returns the first value of the response header key.
+func (r *Response) Header(key string) string {
+	return r.Headers.Get(key)
+}
+
+// ContentType returns the response Content-Type header.
+func (r *Response) ContentType() string {
+	return r.Headers.Get("Content-Type")
+}
+
+// ContentLength returns the Content-Length header, or -1 when it is absent
+// or invalid.
+func (r *Response) ContentLength() int64 {
+	n, err := strconv.ParseInt(r.Headers.Get("Content-Length"), 10, 64)
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
+
+// RateLimitRemaining parses the remaining request quota from the common
+// X-RateLimit-Remaining, X-Rate-Limit-Remaining or RateLimit-Remaining headers.
+func (r *Response) RateLimitRemaining() (int, bool) {
+	for _, key := range []string{"X-RateLimit-Remaining", "X-Rate-Limit-Remaining", "RateLimit-Remaining"} {
+		if v := r.Headers.Get(key); v != "" {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// Cookies parses all Set-Cookie headers of the response.
+func (r *Response) Cookies() []*http.Cookie {
+	return (&http.Response{Header: r.Headers}).Cookies()
+}
+
+// Cookie returns the cookie set by the response with the given name.
+func (r *Response) Cookie(name string) (*http.Cookie, bool) {
+	for _, c := range r.Cookies() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func newResponse(c *Client, res *http.Response) *Response {
+	return &Response{
+		client:     c,
+		res:        res,
+		Status:     res.StatusCode,
+		Headers:    res.Header,
+		Body:       res,
+		StatusText: res.Status,
+		FromCache:  fromCache(res),
+	}
+}
+
+// bodyless reports whether res cannot carry a body.
+func bodyless(res *http.Response) bool {
+	if res.StatusCode == http.StatusNoContent || res.StatusCode == http.StatusNotModified {
+		return true
+	}
+	return res.ContentLength == 0 || (res.Request != nil && res.Request.Method == http.MethodHead)
 }
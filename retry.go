@@ -0,0 +1,236 @@
+package quester
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig controls automatic retries performed by the client.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles on every attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter randomizes each delay to spread out retries from many clients.
+	// It is on when nil; point it to false for fixed delays.
+	Jitter *bool
+	// MaxRetryDuration caps the total time spent on all attempts, including
+	// backoff and reading the body of the final response. A running attempt
+	// is canceled once it is exceeded. Zero means no cap.
+	MaxRetryDuration time.Duration
+	// PerAttemptTimeout bounds each attempt, including reading the body of
+	// the final response, with a fresh child of the request context. The
+	// request context and its timeouts still bound the whole operation, as
+	// does MaxRetryDuration when set. DefaultRetryIf retries attempts that
+	// timed out, unless the request context or retry budget is done.
+	PerAttemptTimeout time.Duration
+	// Backoff computes the delay before each retry. When nil, an
+	// ExponentialBackoff built from BaseDelay, MaxDelay and Jitter is used.
+	Backoff BackoffStrategy
+	// RetryIf decides whether an attempt should be retried.
+	// When nil, DefaultRetryIf is used.
+	RetryIf func(*http.Response, error) bool
+}
+
+// SetRetry enables automatic retries for every request sent by the client.
+// Backoff waits end as soon as the request context is done, and Do returns
+// the context error.
+func (c *Client) SetRetry(cfg RetryConfig) {
+	c.retry = &cfg
+}
+
+// onRetryKey carries the OnRetry callback of a request in its context.
+type onRetryKey struct{}
+
+// OnRetry sets a callback invoked before each retry with the (1-based) attempt
+// that failed, its response or error, and the delay before the next attempt.
+// The response body is drained after the callback returns. It is never called
+// when retries are disabled.
+func (r *Request) OnRetry(fn func(attempt int, res *http.Response, err error, delay time.Duration)) *Request {
+	r.onRetry = fn
+	return r
+}
+
+// DefaultRetryIf retries on connection errors, attempts that exceeded
+// PerAttemptTimeout, 429 Too Many Requests and 5xx responses. Other errors,
+// such as invalid URLs, certificate failures or a canceled context, are
+// returned right away.
+func DefaultRetryIf(res *http.Response, err error) bool {
+	if err != nil {
+		var timeout *attemptTimeoutError
+		if errors.As(err, &timeout) {
+			return true
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		// Certificate failures surface from the handshake and never go away
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			return false
+		}
+		// The server closing the connection before responding shows up as EOF
+		return IsConnectionError(err) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+func (cfg *RetryConfig) shouldRetry(res *http.Response, err error) bool {
+	if cfg.RetryIf != nil {
+		return cfg.RetryIf(res, err)
+	}
+	return DefaultRetryIf(res, err)
+}
+
+// backoff returns the delay to wait after the given (1-based) attempt.
+func (cfg *RetryConfig) backoff(attempt int, prev time.Duration) time.Duration {
+	if cfg.Backoff != nil {
+		return cfg.Backoff.Backoff(attempt, prev)
+	}
+	jitter := cfg.Jitter == nil || *cfg.Jitter
+	return ExponentialBackoff{Base: cfg.BaseDelay, Max: cfg.MaxDelay, Jitter: jitter}.Backoff(attempt, prev)
+}
+
+// send executes req, retrying according to the client's retry config.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.retry == nil || c.retry.MaxAttempts < 2 {
+		return c.attempt(req)
+	}
+
+	if c.retry.MaxRetryDuration <= 0 {
+		return c.retryLoop(req, time.Now())
+	}
+
+	// The budget also cuts off an attempt that is still running
+	start := time.Now()
+	ctx, cancel := context.WithDeadline(req.Context(), start.Add(c.retry.MaxRetryDuration))
+	res, err := c.retryLoop(req.WithContext(ctx), start)
+	if err != nil || res == nil {
+		cancel()
+		return res, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// retryLoop sends attempts of req until one succeeds or retries run out.
+func (c *Client) retryLoop(req *http.Request, start time.Time) (*http.Response, error) {
+	cfg := c.retry
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		res, err := c.attempt(req)
+		if attempt >= cfg.MaxAttempts || !canReplay(req) || !cfg.shouldRetry(res, err) {
+			return res, err
+		}
+
+		delay = cfg.backoff(attempt, delay)
+		if res != nil {
+			if d, ok := retryAfter(res); ok && d > delay {
+				delay = d
+			}
+		}
+
+		// Give up with the last result once the retry budget would be exceeded
+		if cfg.MaxRetryDuration > 0 && time.Since(start)+delay > cfg.MaxRetryDuration {
+			return res, err
+		}
+
+		if fn, ok := req.Context().Value(onRetryKey{}).(func(int, *http.Response, error, time.Duration)); ok {
+			fn(attempt, res, err, delay)
+		}
+
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		// Rewind the body for the next attempt
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// attemptTimeoutError is returned when an attempt exceeded PerAttemptTimeout
+// while the request context was still live.
+type attemptTimeoutError struct {
+	err error
+}
+
+func (e *attemptTimeoutError) Error() string {
+	return "quester: attempt timed out: " + e.err.Error()
+}
+
+func (e *attemptTimeoutError) Unwrap() error {
+	return e.err
+}
+
+// attempt sends a single attempt, bounded by the per-attempt timeout.
+func (c *Client) attempt(req *http.Request) (*http.Response, error) {
+	if c.retry == nil || c.retry.PerAttemptTimeout <= 0 {
+		return c.roundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), c.retry.PerAttemptTimeout)
+	res, err := c.roundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if ctx.Err() != nil && req.Context().Err() == nil {
+			err = &attemptTimeoutError{err: err}
+		}
+		return nil, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// canReplay reports whether the request body can be sent again.
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryAfter parses the Retry-After header as seconds or an HTTP date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done, whichever comes first. All waits in
+// the package go through it, so none of them ignores cancellation.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,204 @@
+package quester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func unavailableServer(t *testing.T, attempts *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRetryBackoffCanceled(t *testing.T) {
+	var attempts atomic.Int32
+	srv := unavailableServer(t, &attempts)
+	c := NewClient(srv.URL)
+	c.SetRetry(RetryConfig{MaxAttempts: 3, BaseDelay: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	_, err := c.Get("/").
+		WithContext(ctx).
+		OnRetry(func(int, *http.Response, error, time.Duration) { cancel() }).
+		Do(nil)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("backoff not interrupted, took %v", elapsed)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("got %d attempts, want 1", n)
+	}
+}
+
+func TestRetryBackoffDeadline(t *testing.T) {
+	var attempts atomic.Int32
+	srv := unavailableServer(t, &attempts)
+	c := NewClient(srv.URL)
+	c.SetRetry(RetryConfig{MaxAttempts: 3, BaseDelay: time.Minute})
+
+	start := time.Now()
+	_, err := c.Get("/").SetTimeout(50 * time.Millisecond).Do(nil)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("backoff not interrupted, took %v", elapsed)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("got %d attempts, want 1", n)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	if err := sleep(context.Background(), time.Millisecond); err != nil {
+		t.Fatalf("uncanceled sleep: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if err := sleep(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("sleep not interrupted, took %v", elapsed)
+	}
+
+	// A done context wins even without a delay
+	if err := sleep(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("zero delay: got %v, want context.Canceled", err)
+	}
+}
+
+func TestMaxRetryDurationCutsOffAttempt(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) > 1 {
+			// Later attempts hang until the client gives up
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL)
+	c.SetRetry(RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxRetryDuration: 200 * time.Millisecond})
+
+	start := time.Now()
+	_, err := c.Get("/").Do(nil)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("running attempt not cut off, took %v", elapsed)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Fatalf("got %d attempts, want 2", n)
+	}
+}
+
+func TestDefaultBackoffJitter(t *testing.T) {
+	const base = 100 * time.Millisecond
+	fixed := false
+	tests := []struct {
+		name   string
+		jitter *bool
+		want   func(time.Duration) bool
+	}{
+		{"default", nil, func(d time.Duration) bool { return d >= base/2 && d <= base }},
+		{"disabled", &fixed, func(d time.Duration) bool { return d == base }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := RetryConfig{BaseDelay: base, Jitter: tt.jitter}
+			varied := false
+			for range 50 {
+				d := cfg.backoff(1, 0)
+				if !tt.want(d) {
+					t.Fatalf("delay %v out of range", d)
+				}
+				varied = varied || d != base
+			}
+			if tt.jitter == nil && !varied {
+				t.Fatal("default backoff is not jittered")
+			}
+		})
+	}
+}
+
+func TestDefaultRetryIfErrors(t *testing.T) {
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://example.com", Err: err}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"refused", urlErr(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}), true},
+		{"reset", urlErr(syscall.ECONNRESET), true},
+		{"dns", urlErr(&net.DNSError{Err: "no such host", Name: "example.com"}), true},
+		{"eof", urlErr(io.EOF), true},
+		{"attempt timeout", &attemptTimeoutError{err: urlErr(context.DeadlineExceeded)}, true},
+		{"canceled", urlErr(context.Canceled), false},
+		{"deadline", urlErr(context.DeadlineExceeded), false},
+		{"bad scheme", urlErr(errors.New(`unsupported protocol scheme "ftp"`)), false},
+		{"body too large", &BodyTooLargeError{Limit: 10}, false},
+		{"other", fmt.Errorf("quester: encode body: %w", errors.New("boom")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRetryIf(nil, tt.err); got != tt.want {
+				t.Fatalf("DefaultRetryIf(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRetryIfCertificateError(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	// The default client does not trust the test server certificate
+	_, err := NewClient(srv.URL).Get("/").Do(nil)
+	if err == nil {
+		t.Fatal("expected a certificate error")
+	}
+	if DefaultRetryIf(nil, err) {
+		t.Fatalf("certificate error %v is retried", err)
+	}
+}
+
+func TestDefaultRetryIfStatus(t *testing.T) {
+	for status, want := range map[int]bool{200: false, 404: false, 429: true, 500: true, 503: true} {
+		if got := DefaultRetryIf(&http.Response{StatusCode: status}, nil); got != want {
+			t.Errorf("DefaultRetryIf(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package quester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaError lists the ways a response body fails its JSON schema.
+type SchemaError struct {
+	Errors []string
+}
+
+func (e *SchemaError) Error() string {
+	return "quester: response does not match schema: " + strings.Join(e.Errors, "; ")
+}
+
+// ValidateSchema validates JSON response bodies against schema before they
+// are decoded; a mismatch makes Do return a *SchemaError. Non-JSON responses
+// are not validated.
+//
+// Supported keywords are type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf,
+// anyOf and oneOf. Other keywords, including $ref, are ignored.
+func (r *Request) ValidateSchema(schema []byte) *Request {
+	r.schema = schema
+	return r
+}
+
+// validateSchema validates the JSON document data against schema.
+func validateSchema(schema, data []byte) error {
+	var s any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("quester: invalid JSON schema: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	var errs []string
+	validateValue(s, v, "$", &errs)
+	if len(errs) > 0 {
+		return &SchemaError{Errors: errs}
+	}
+	return nil
+}
+
+func validateValue(schema, v any, path string, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	s, ok := schema.(map[string]any)
+	if !ok {
+		if b, ok := schema.(bool); ok && !b {
+			fail("no value is allowed")
+		}
+		return
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, v) {
+		fail("expected %v, got %s", t, jsonType(v))
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value is not one of %v", enum)
+		}
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, v) {
+		fail("expected constant %v", c)
+	}
+
+	for _, sub := range schemaList(s["allOf"]) {
+		validateValue(sub, v, path, errs)
+	}
+	if subs := schemaList(s["anyOf"]); len(subs) > 0 && countMatches(subs, v) == 0 {
+		fail("value matches none of anyOf")
+	}
+	if subs := schemaList(s["oneOf"]); len(subs) > 0 {
+		if n := countMatches(subs, v); n != 1 {
+			fail("value matches %d of oneOf, expected exactly 1", n)
+		}
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		if required, ok := s["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := val[fmt.Sprint(name)]; !ok {
+					fail("missing required field %q", name)
+				}
+			}
+		}
+		props, _ := s["properties"].(map[string]any)
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := props[k]; ok {
+				validateValue(ps, val[k], path+"."+k, errs)
+				continue
+			}
+			switch ap := s["additionalProperties"].(type) {
+			case bool:
+				if !ap {
+					fail("unexpected field %q", k)
+				}
+			case map[string]any:
+				validateValue(ap, val[k], path+"."+k, errs)
+			}
+		}
+	case []any:
+		if n, ok := schemaNumber(s, "minItems"); ok && float64(len(val)) < n {
+			fail("expected at least %v items, got %d", n, len(val))
+		}
+		if n, ok := schemaNumber(s, "maxItems"); ok && float64(len(val)) > n {
+			fail("expected at most %v items, got %d", n, len(val))
+		}
+		if items, ok := s["items"]; ok {
+			for i, item := range val {
+				validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(val))
+		if n, ok := schemaNumber(s, "minLength"); ok && length < n {
+			fail("expected at least %v characters", n)
+		}
+		if n, ok := schemaNumber(s, "maxLength"); ok && length > n {
+			fail("expected at most %v characters", n)
+		}
+		if p, ok := s["pattern"].(string); ok {
+			if re, err := regexp.Compile(p); err == nil && !re.MatchString(val) {
+				fail("value does not match pattern %q", p)
+			}
+		}
+	case json.Number:
+		f, _ := val.Float64()
+		if n, ok := schemaNumber(s, "minimum"); ok && f < n {
+			fail("value %v is less than minimum %v", val, n)
+		}
+		if n, ok := schemaNumber(s, "maximum"); ok && f > n {
+			fail("value %v is greater than maximum %v", val, n)
+		}
+		if n, ok := schemaNumber(s, "exclusiveMinimum"); ok && f <= n {
+			fail("value %v must be greater than %v", val, n)
+		}
+		if n, ok := schemaNumber(s, "exclusiveMaximum"); ok && f >= n {
+			fail("value %v must be less than %v", val, n)
+		}
+	}
+}
+
+func countMatches(schemas []any, v any) int {
+	n := 0
+	for _, sub := range schemas {
+		var errs []string
+		validateValue(sub, v, "", &errs)
+		if len(errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func schemaList(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func schemaNumber(s map[string]any, key string) (float64, bool) {
+	n, ok := s[key].(float64)
+	return n, ok
+}
+
+func matchesType(t, v any) bool {
+	switch t := t.(type) {
+	case string:
+		return t == jsonType(v) || (t == "number" && jsonType(v) == "integer")
+	case []any:
+		for _, tt := range t {
+			if matchesType(tt, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func jsonType(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := val.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// jsonEqual compares a schema value with a decoded document value, treating
+// numbers by value.
+func jsonEqual(schemaVal, v any) bool {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		sf, ok := schemaVal.(float64)
+		return err == nil && ok && f == sf
+	}
+	return reflect.DeepEqual(schemaVal, v)
+}
@@ -0,0 +1,70 @@
+package quester
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// sizedBody is a streamed body with a declared length.
+type sizedBody struct {
+	r      io.Reader
+	length int64
+	start  int64 // offset to rewind seekable readers to
+}
+
+// SetBodyWithLength sets a streamed body of exactly length bytes, sent with
+// a Content-Length header instead of chunked encoding. When r is an io.Seeker
+// the body is rewound for retries. Sending fails if r does not yield exactly
+// length bytes.
+func (r *Request) SetBodyWithLength(body io.Reader, length int64) *Request {
+	r.body = &sizedBody{r: body, length: length}
+	return r
+}
+
+// open records the start offset of seekable readers and returns the checked body.
+func (b *sizedBody) open() (io.Reader, error) {
+	if s, ok := b.r.(io.Seeker); ok {
+		off, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		b.start = off
+	}
+	return &lengthChecker{r: b.r, length: b.length}, nil
+}
+
+// apply sets the declared length and rewind support on req.
+func (b *sizedBody) apply(req *http.Request) {
+	req.ContentLength = b.length
+	if b.length == 0 {
+		req.Body = http.NoBody
+	}
+	if s, ok := b.r.(io.Seeker); ok {
+		req.GetBody = func() (io.ReadCloser, error) {
+			if _, err := s.Seek(b.start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(&lengthChecker{r: b.r, length: b.length}), nil
+		}
+	}
+}
+
+// lengthChecker fails when the reader yields more or fewer bytes than declared.
+type lengthChecker struct {
+	r      io.Reader
+	length int64
+	n      int64
+}
+
+func (c *lengthChecker) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.n > c.length {
+		return n, fmt.Errorf("quester: body is longer than declared length %d", c.length)
+	}
+	if err == io.EOF && c.n != c.length {
+		return n, fmt.Errorf("quester: body has %d bytes, declared length %d", c.n, c.length)
+	}
+	return n, err
+}
@@ -0,0 +1,195 @@
+package quester
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEEvent is a single Server-Sent Event.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// streamBody is a live response body that releases the request context
+// when closed.
+type streamBody struct {
+	io.ReadCloser
+	cancel func()
+	once   sync.Once
+	done   chan struct{}
+}
+
+func (b *streamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		close(b.done)
+		if b.cancel != nil {
+			b.cancel()
+		}
+	})
+	return err
+}
+
+// Stream sends the request and returns the live response body without
+// reading it. The caller must close the body; cancelling the request
+// context stops the stream.
+func (r *Request) Stream() (*Response, io.ReadCloser, error) {
+	start := time.Now()
+	res, err := r.send()
+	if err != nil {
+		r.cleanup()
+		return nil, nil, err
+	}
+
+	body := r.liveBody(res)
+	resp := newResponse(r.client, res)
+	resp.stream = body
+	resp.ReceivedAt = time.Now()
+	resp.Attempts = int(r.attempts.Load())
+	resp.Duration = resp.ReceivedAt.Sub(start)
+	return resp, body, nil
+}
+
+// DoRaw runs the full request pipeline and returns the unread response. The
+// caller must close the response body.
+func (r *Request) DoRaw() (*http.Response, error) {
+	res, err := r.send()
+	if err != nil {
+		r.cleanup()
+		return nil, err
+	}
+	res.Body = r.liveBody(res)
+	return res, nil
+}
+
+// liveBody wraps res.Body so closing it releases the request context.
+func (r *Request) liveBody(res *http.Response) *streamBody {
+	body := &streamBody{ReadCloser: res.Body, cancel: r.cancel, done: make(chan struct{})}
+	r.cancel = nil
+	return body
+}
+
+// Cancel aborts a response returned by Stream: it cancels the request context
+// and closes the body, unblocking any pending read. It is safe to call several
+// times, and a no-op for responses whose body was already read by Do.
+func (r *Response) Cancel() {
+	if r.stream == nil {
+		return
+	}
+	if r.stream.cancel != nil {
+		r.stream.cancel()
+	}
+	r.stream.Close()
+}
+
+// Events parses a text/event-stream body returned by Stream. The channel is
+// closed when the stream ends or the body is closed.
+func (r *Response) Events() (<-chan SSEEvent, error) {
+	if r.stream == nil {
+		return nil, errors.New("quester: response is not a stream, use Request.Stream")
+	}
+	if ct := r.Headers.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		return nil, errors.New("quester: unexpected content type " + strconv.Quote(ct) + " for event stream")
+	}
+
+	ch := make(chan SSEEvent)
+	go func() {
+		defer close(ch)
+
+		br := bufio.NewReader(r.stream)
+		var ev SSEEvent
+		var data strings.Builder
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil && line == "" {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			// A blank line dispatches the buffered event
+			if line == "" {
+				if data.Len() > 0 {
+					ev.Data = strings.TrimSuffix(data.String(), "\n")
+					if ev.Event == "" {
+						ev.Event = "message"
+					}
+					select {
+					case ch <- ev:
+					case <-r.stream.done:
+						return
+					}
+				}
+				ev = SSEEvent{ID: ev.ID}
+				data.Reset()
+				continue
+			}
+			if strings.HasPrefix(line, ":") {
+				continue
+			}
+
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				ev.Event = value
+			case "data":
+				data.WriteString(value)
+				data.WriteByte('\n')
+			case "id":
+				if !strings.ContainsRune(value, 0) {
+					ev.ID = value
+				}
+			case "retry":
+				if ms, err := strconv.Atoi(value); err == nil {
+					ev.Retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// DecodeEach reads an application/x-ndjson body one JSON value at a time and
+// calls fn for each, stopping at the end of the body or at the first error.
+// Streamed responses are read incrementally; cancelling the request context
+// halts iteration.
+func (r *Response) DecodeEach(fn func(json.RawMessage) error) error {
+	if ct := mediaType(r.Headers.Get("Content-Type")); ct != "application/x-ndjson" {
+		return errors.New("quester: unexpected content type " + strconv.Quote(ct) + " for ndjson")
+	}
+
+	var body io.Reader
+	switch {
+	case r.stream != nil:
+		body = r.stream
+	case r.raw != nil:
+		body = bytes.NewReader(r.raw)
+	default:
+		return errors.New("quester: response body is not available, use Request.Stream or KeepRawBody")
+	}
+
+	dec := json.NewDecoder(body)
+	for {
+		var msg json.RawMessage
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package quester
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trace event names.
+const (
+	TraceDNSStart          = "DNSStart"
+	TraceDNSDone           = "DNSDone"
+	TraceConnectStart      = "ConnectStart"
+	TraceConnectDone       = "ConnectDone"
+	TraceTLSHandshakeStart = "TLSHandshakeStart"
+	TraceTLSHandshakeDone  = "TLSHandshakeDone"
+	TraceGotConn           = "GotConn"
+	TraceFirstResponseByte = "GotFirstResponseByte"
+)
+
+// TraceEvent is a single HTTP trace event emitted while a request is sent.
+type TraceEvent struct {
+	Name string
+	Time time.Time
+	Addr string
+	// Elapsed is the time since the matching start event, or since the
+	// request started for GotFirstResponseByte.
+	Elapsed time.Duration
+	Err     error
+
+	// Connection reuse details, set for GotConn events.
+	Reused   bool
+	WasIdle  bool
+	IdleTime time.Duration
+}
+
+// SetTraceWriter enables tracing and writes trace events to w.
+func (r *Request) SetTraceWriter(w io.Writer) *Request {
+	r.enableTrace = true
+	r.traceWriter = w
+	return r
+}
+
+// SetTraceHook enables tracing and passes every trace event to fn.
+func (r *Request) SetTraceHook(fn func(TraceEvent)) *Request {
+	r.enableTrace = true
+	r.traceHook = fn
+	return r
+}
+
+type tracer struct {
+	mu           sync.Mutex
+	emit         func(TraceEvent)
+	start        time.Time
+	dnsStart     time.Time
+	tlsStart     time.Time
+	connectStart map[string]time.Time
+}
+
+// clientTrace builds the httptrace hooks that route events to the configured sink.
+func (r *Request) clientTrace() *httptrace.ClientTrace {
+	emit := r.traceHook
+	if emit == nil {
+		w := r.traceWriter
+		if w == nil {
+			w = os.Stderr
+		}
+		emit = func(e TraceEvent) { writeTraceEvent(w, e) }
+	}
+
+	t := &tracer{emit: emit, start: time.Now(), connectStart: map[string]time.Time{}}
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			t.event(TraceDNSStart, info.Host, nil, func(now time.Time) time.Duration {
+				t.dnsStart = now
+				return 0
+			})
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			addrs := make([]string, len(info.Addrs))
+			for i, a := range info.Addrs {
+				addrs[i] = a.String()
+			}
+			t.event(TraceDNSDone, strings.Join(addrs, ","), info.Err, func(now time.Time) time.Duration {
+				return now.Sub(t.dnsStart)
+			})
+		},
+		ConnectStart: func(network, addr string) {
+			t.event(TraceConnectStart, addr, nil, func(now time.Time) time.Duration {
+				t.connectStart[addr] = now
+				return 0
+			})
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.event(TraceConnectDone, addr, err, func(now time.Time) time.Duration {
+				return now.Sub(t.connectStart[addr])
+			})
+		},
+		TLSHandshakeStart: func() {
+			t.event(TraceTLSHandshakeStart, "", nil, func(now time.Time) time.Duration {
+				t.tlsStart = now
+				return 0
+			})
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			t.event(TraceTLSHandshakeDone, "", err, func(now time.Time) time.Duration {
+				return now.Sub(t.tlsStart)
+			})
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			var addr string
+			if info.Conn != nil {
+				addr = info.Conn.RemoteAddr().String()
+			}
+			t.emit(TraceEvent{
+				Name:     TraceGotConn,
+				Time:     time.Now(),
+				Addr:     addr,
+				Reused:   info.Reused,
+				WasIdle:  info.WasIdle,
+				IdleTime: info.IdleTime,
+			})
+		},
+		GotFirstResponseByte: func() {
+			t.event(TraceFirstResponseByte, "", nil, func(now time.Time) time.Duration {
+				return now.Sub(t.start)
+			})
+		},
+	}
+}
+
+// event records timing state under the lock and emits the event.
+func (t *tracer) event(name, addr string, err error, elapsed func(now time.Time) time.Duration) {
+	now := time.Now()
+	t.mu.Lock()
+	e := TraceEvent{Name: name, Time: now, Addr: addr, Elapsed: elapsed(now), Err: err}
+	t.mu.Unlock()
+	t.emit(e)
+}
+
+func writeTraceEvent(w io.Writer, e TraceEvent) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[TRACE] %s %s", e.Time.Format(time.RFC3339Nano), e.Name)
+	if e.Addr != "" {
+		fmt.Fprintf(&b, " addr=%s", e.Addr)
+	}
+	if e.Elapsed > 0 {
+		fmt.Fprintf(&b, " elapsed=%s", e.Elapsed)
+	}
+	if e.Name == TraceGotConn {
+		fmt.Fprintf(&b, " reused=%t was_idle=%t", e.Reused, e.WasIdle)
+		if e.WasIdle {
+			fmt.Fprintf(&b, " idle=%s", e.IdleTime)
+		}
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, " err=%v", e.Err)
+	}
+	b.WriteByte('\n')
+	_, _ = io.WriteString(w, b.String())
+}
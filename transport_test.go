@@ -0,0 +1,70 @@
+package quester
+
+import (
+	"crypto/x509"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTLSSettingsCloneInjectedTransport(t *testing.T) {
+	shared := &http.Transport{}
+	c := NewClient("https://example.com")
+	c.SetTransport(shared)
+
+	pool := x509.NewCertPool()
+	c.InsecureSkipVerify(true)
+	c.SetRootCAs(pool)
+
+	// Cloning lets net/http fill in HTTP/2 defaults, but nothing else
+	if cfg := shared.TLSClientConfig; cfg != nil && (cfg.InsecureSkipVerify || cfg.RootCAs != nil) {
+		t.Fatal("injected transport modified")
+	}
+	tr := c.transport()
+	if tr == shared {
+		t.Fatal("injected transport still in use")
+	}
+	if cfg := tr.TLSClientConfig; cfg == nil || !cfg.InsecureSkipVerify || cfg.RootCAs != pool {
+		t.Fatalf("settings not applied to the clone: %+v", cfg)
+	}
+}
+
+func TestSetProtocolsClonesInjectedTransport(t *testing.T) {
+	shared := &http.Transport{}
+	c := NewClient("https://example.com")
+	c.SetTransport(shared)
+
+	if err := c.SetProtocols("http/1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if shared.Protocols != nil {
+		t.Fatal("injected transport modified")
+	}
+	if c.transport().Protocols == nil {
+		t.Fatal("protocols not applied to the clone")
+	}
+}
+
+func TestTransportSettingsCloneInjectedTransport(t *testing.T) {
+	shared := &http.Transport{}
+	c := NewClient("http://example.com")
+	c.SetTransport(shared)
+
+	if err := c.SetProxy("http://proxy.example.com:8080"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetDialTimeout(time.Second)
+	c.SetTLSHandshakeTimeout(time.Second)
+	c.SetResponseHeaderTimeout(time.Second)
+	c.SetExpectContinueTimeout(time.Second)
+
+	if shared.Proxy != nil || shared.DialContext != nil || shared.TLSHandshakeTimeout != 0 ||
+		shared.ResponseHeaderTimeout != 0 || shared.ExpectContinueTimeout != 0 {
+		t.Fatal("injected transport modified")
+	}
+	tr := c.transport()
+	if tr.Proxy == nil || tr.DialContext == nil || tr.TLSHandshakeTimeout != time.Second ||
+		tr.ResponseHeaderTimeout != time.Second || tr.ExpectContinueTimeout != time.Second {
+		t.Fatal("settings not applied to the clone")
+	}
+}
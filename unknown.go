@@ -0,0 +1,153 @@
+package quester
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// DisallowUnknownFields makes Do fail when a JSON response has fields that
+// the result type does not define.
+func (r *Request) DisallowUnknownFields() *Request {
+	r.disallowUnknown = true
+	return r
+}
+
+// OnUnknownField calls fn with the path, e.g. "items.price", of every field
+// of a JSON response that the result type does not define, without failing
+// Do.
+func (r *Request) OnUnknownField(fn func(path string)) *Request {
+	r.onUnknownField = fn
+	return r
+}
+
+// checksUnknownFields reports whether the response is decoded by decodeJSON.
+func (r *Request) checksUnknownFields(contentType string) bool {
+	return (r.disallowUnknown || r.onUnknownField != nil) && isJSON(contentType)
+}
+
+// decodeJSON decodes body into result, checking for unknown fields.
+func (r *Request) decodeJSON(contentType string, body io.Reader, result any) error {
+	body, err := transcode(contentType, body)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if r.onUnknownField != nil {
+		unknownFields(data, reflect.TypeOf(result), "", r.onUnknownField)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if r.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(result)
+}
+
+// unknownFields reports the fields of data that t does not define.
+func unknownFields(data []byte, t reflect.Type, path string, report func(string)) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(data, &obj) != nil {
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range slices.Sorted(maps.Keys(obj)) {
+			ft, ok := lookupField(fields, key)
+			if !ok {
+				report(joinPath(path, key))
+				continue
+			}
+			unknownFields(obj[key], ft, joinPath(path, key), report)
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return
+		}
+		for _, item := range items {
+			unknownFields(item, t.Elem(), path, report)
+		}
+	case reflect.Map:
+		var obj map[string]json.RawMessage
+		if json.Unmarshal(data, &obj) != nil {
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(obj)) {
+			unknownFields(obj[key], t.Elem(), joinPath(path, key), report)
+		}
+	}
+}
+
+// jsonFields returns the JSON field names of struct type t, following the
+// encoding/json rules for tags and embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// lookupField matches key like encoding/json: exactly, then ignoring case.
+func lookupField(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if t, ok := fields[key]; ok {
+		return t, true
+	}
+	for name, t := range fields {
+		if strings.EqualFold(name, key) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,54 @@
+package quester
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// StrictMode makes requests fail before being sent when they have an empty
+// method, no base URL while the path is relative, or a body on a GET or HEAD.
+// Without strict mode such requests are sent as built.
+func (c *Client) StrictMode(strict bool) {
+	c.strict = strict
+}
+
+// validate reports an invalid method, and obvious mistakes in the request
+// when the client is strict.
+func (r *Request) validate() error {
+	if r.method != "" && !validToken(r.method) {
+		return fmt.Errorf("quester: invalid method %q", r.method)
+	}
+	if !r.client.strict {
+		return nil
+	}
+	if r.method == "" {
+		return errors.New("quester: request method is empty")
+	}
+	if r.client.BaseURL == "" && r.pageURL == nil {
+		if u, err := url.Parse(r.path); err != nil || !u.IsAbs() {
+			return fmt.Errorf("quester: no base URL set for relative path %q", r.path)
+		}
+	}
+	if r.body != nil && (r.method == http.MethodGet || r.method == http.MethodHead) {
+		return fmt.Errorf("quester: %s request must not have a body", r.method)
+	}
+	return nil
+}
+
+// validToken reports whether s is a token as defined by RFC 7230, section
+// 3.2.6.
+func validToken(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", rune(c)) {
+			return false
+		}
+	}
+	return s != ""
+}